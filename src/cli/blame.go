@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"strconv"
+
+	"repotools/src/git"
+	"repotools/src/metrics"
+
+	"github.com/spf13/cobra"
+)
+
+func newBlameCmd() *cobra.Command {
+	var summary, code, asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "blame <file> [start] [end]",
+		Short: "Blame a file or range, grouping consecutive lines by commit",
+		Long: `Blame a file or line range with git blame --porcelain, printing one line
+per run of consecutive lines from the same commit: range, short sha, date,
+author and subject. --summary prints each author's share of the lines
+instead.
+
+start and end may be line numbers or function names (found with the
+fn-spans patterns, Type.Method for a Go method); a function as start with
+no end blames the whole function.`,
+		Args: cobra.RangeArgs(1, 3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := args[0]
+			start, end := 0, 0
+			if len(args) >= 2 {
+				span, err := blameBound(path, args[1])
+				if err != nil {
+					return err
+				}
+				start = span.Start
+				if len(args) == 2 && span.Name != "" {
+					end = span.End
+				}
+			}
+			if len(args) >= 3 {
+				span, err := blameBound(path, args[2])
+				if err != nil {
+					return err
+				}
+				end = span.End
+			}
+
+			lines, err := git.Blame(path, start, end)
+			if err != nil {
+				return err
+			}
+			if summary {
+				return git.WriteBlameSummary(cmd.OutOrStdout(), git.SummarizeBlame(lines), asJSON)
+			}
+			return git.WriteBlame(cmd.OutOrStdout(), git.GroupBlame(lines), code, asJSON)
+		},
+	}
+
+	cmd.Flags().BoolVar(&summary, "summary", false, "Print lines, share, commits and last date per author")
+	cmd.Flags().BoolVar(&code, "code", false, "Print the numbered lines under each commit")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print groups (or the summary) as JSON")
+	return cmd
+}
+
+// blameBound resolves a range argument: a line number, as a span of one
+// line, or a function name.
+func blameBound(path, arg string) (metrics.FnSpan, error) {
+	if n, err := strconv.Atoi(arg); err == nil {
+		return metrics.FnSpan{Start: n, End: n}, nil
+	}
+	return metrics.FindFnSpan(path, arg)
+}
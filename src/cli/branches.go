@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"repotools/src/git"
+	"repotools/src/github"
+	"repotools/src/mcp"
+
+	"github.com/spf13/cobra"
+)
+
+func newBranchesCmd() *cobra.Command {
+	var base, stale string
+	var asJSON, noPRs, pruneMerged, yes bool
+
+	cmd := &cobra.Command{
+		Use:     "branches",
+		Aliases: []string{"br"},
+		Short:   "List local branches with upstream, merge state, PR and last commit",
+		Long: `List local branches with their last commit, upstream and ahead/behind it,
+whether they are merged into the base branch, and the newest PR for the
+branch (via gh). Merged, gone (upstream deleted) and stale branches are
+flagged for cleanup.
+
+--prune-merged lists the merged branches that would be deleted; add --yes
+to delete them with "git branch -d". Branches checked out in a worktree are
+kept.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			base = baseBranch(base)
+			var staleBefore time.Time
+			if stale != "" {
+				var err error
+				if staleBefore, err = git.WindowStart(stale, time.Now()); err != nil {
+					return err
+				}
+			}
+			branches, err := git.ListBranches(base, staleBefore)
+			if err != nil {
+				return err
+			}
+			w := cmd.OutOrStdout()
+
+			if pruneMerged {
+				return pruneBranches(w, base, git.Prunable(branches), yes)
+			}
+
+			if !noPRs {
+				prs, err := github.HeadPRs()
+				if err != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "PRs unavailable: %v\n", err)
+				}
+				for i := range branches {
+					if pr, ok := prs[branches[i].Name]; ok {
+						branches[i].PRNumber, branches[i].PRState = pr.Number, pr.State
+					}
+				}
+			}
+			return git.WriteBranches(w, branches, asJSON)
+		},
+	}
+
+	cmd.Flags().StringVarP(&base, "base", "b", "", "Branch to check merges into (default: the configured base)")
+	cmd.Flags().StringVar(&stale, "stale", "90d", "Flag branches with no commits in this window (Nd, Nw, Nm, Ny or a date; empty to disable)")
+	cmd.Flags().BoolVar(&noPRs, "no-prs", false, "Skip looking up PRs with gh")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print branches as JSON")
+	cmd.Flags().BoolVar(&pruneMerged, "prune-merged", false, "List branches merged into the base for deletion")
+	cmd.Flags().BoolVar(&yes, "yes", false, "With --prune-merged, delete the branches")
+	// Deleting branches needs a person to confirm, so MCP clients cannot.
+	for _, name := range []string{"prune-merged", "yes"} {
+		cmd.Flags().SetAnnotation(name, mcp.SkipAnnotation, []string{"true"})
+	}
+	return cmd
+}
+
+// pruneBranches lists the branches, or deletes them with yes. A branch that
+// fails to delete is reported and the rest are still deleted.
+func pruneBranches(w io.Writer, base string, prunable []git.BranchInfo, yes bool) error {
+	if len(prunable) == 0 {
+		fmt.Fprintf(w, "No branches merged into %s to delete.\n", base)
+		return nil
+	}
+	failed := 0
+	for _, b := range prunable {
+		if !yes {
+			fmt.Fprintf(w, "would delete %s (%s)\n", b.Name, b.Last.Hash)
+			continue
+		}
+		if err := git.DeleteBranch(b.Name); err != nil {
+			fmt.Fprintf(w, "failed: %v\n", err)
+			failed++
+			continue
+		}
+		fmt.Fprintf(w, "deleted %s (was %s)\n", b.Name, b.Last.Hash)
+	}
+	if !yes {
+		fmt.Fprintln(w, "Rerun with --yes to delete them.")
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d branches not deleted", failed, len(prunable))
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+
+	"repotools/src/output"
+
+	"github.com/spf13/cobra"
+)
+
+// streamAnnotation marks a command whose output is a protocol stream and
+// must never be truncated.
+const streamAnnotation = "output-stream"
+
+// selfBudgetAnnotation marks a command that shares the budget between its
+// sections itself, so its output is not truncated again as a whole.
+const selfBudgetAnnotation = "output-self-budget"
+
+var budget output.Budget
+
+func addBudgetFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().IntVar(&budget.MaxLines, "max-lines", 0, "Truncate output to N lines (0 for no limit)")
+	cmd.PersistentFlags().IntVar(&budget.MaxBytes, "max-bytes", 0, "Truncate output to N bytes (0 for no limit)")
+}
+
+// applyBudget wraps every command under root so that, when a budget is set,
+// its output is buffered and cut to the budget with an omission marker.
+func applyBudget(root *cobra.Command) {
+	for _, c := range root.Commands() {
+		applyBudget(c)
+		budgetCommand(c)
+	}
+}
+
+// budgetCommand wraps the run function of c, unless it streams its output
+// or applies the budget itself.
+func budgetCommand(c *cobra.Command) {
+	if c.Annotations[streamAnnotation] != "" || c.Annotations[selfBudgetAnnotation] != "" {
+		return
+	}
+	if c.RunE != nil {
+		c.RunE = budgeted(c.RunE)
+	} else if run := c.Run; run != nil {
+		c.Run = nil
+		c.RunE = budgeted(func(cmd *cobra.Command, args []string) error {
+			run(cmd, args)
+			return nil
+		})
+	}
+}
+
+func budgeted(run func(*cobra.Command, []string) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if cmd.DisableFlagParsing {
+			var err error
+			if args, err = takeGlobalArgs(cmd, args); err != nil {
+				return err
+			}
+		}
+		// Macros build fresh command trees that reset the flag variables,
+		// so the budget is read before running.
+		b := budget
+		if !b.Limited() {
+			return run(cmd, args)
+		}
+		out := cmd.OutOrStdout()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		err := run(cmd, args)
+		cmd.SetOut(out)
+		io.WriteString(out, output.Truncate(buf.String(), b))
+		return err
+	}
+}
@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"repotools/src/metrics"
+
+	"github.com/spf13/cobra"
+)
+
+func newComplexityCmd() *cobra.Command {
+	var opts metrics.ComplexityOptions
+
+	cmd := &cobra.Command{
+		Use:     "complexity paths...",
+		Aliases: []string{"cx"},
+		Short:   "Cyclomatic and cognitive complexity per function",
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return metrics.RunComplexity(cmd.OutOrStdout(), args, opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Glob, "glob", "g", "", "Include files matching glob")
+	cmd.Flags().StringVarP(&opts.ExcludePath, "exclude-path", "E", "", "Exclude files with paths matching regex")
+	cmd.Flags().StringVarP(&opts.Pattern, "pattern", "p", "", "Regex for function defs (group 1 = name); forces heuristic scoring")
+	cmd.Flags().StringVarP(&opts.Sort, "sort", "s", "cyclomatic", "Sort by cyclomatic, cognitive, lines or file")
+	cmd.Flags().IntVarP(&opts.Threshold, "threshold", "t", 0, "Hide functions whose sort metric is below this")
+	cmd.Flags().IntVarP(&opts.Top, "top", "n", 0, "Show only the N worst functions")
+	return cmd
+}
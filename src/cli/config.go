@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"strings"
+
+	"repotools/src/config"
+	"repotools/src/fs"
+	"repotools/src/github"
+	"repotools/src/metrics"
+	"repotools/src/tickets"
+
+	"github.com/spf13/cobra"
+)
+
+// configuredBase stands for the configured base branch where a flag needs
+// a default value before the config is loaded.
+const configuredBase = "(base)"
+
+// builtinConfig holds the defaults a config file overrides.
+var builtinConfig = config.Config{
+	Base:        "master",
+	PRSections:  github.AllSections,
+	TestMarkers: metrics.DefaultTestMarkers,
+	FnPatterns:  metrics.DefaultFnPatterns,
+}
+
+// cfg is the effective config, loaded before any command runs.
+var cfg = &builtinConfig
+
+// loadConfig reads the config files and feeds their values into the
+// package defaults.
+func loadConfig() error {
+	c, err := config.Load(builtinConfig)
+	if err != nil {
+		return err
+	}
+	if _, err := github.ValidateSections(strings.Join(c.PRSections, ",")); err != nil {
+		return err
+	}
+	cfg = c
+	metrics.DefaultTestMarkers = c.TestMarkers
+	metrics.DefaultFnPatterns = c.FnPatterns
+	metrics.ExcludeNames = c.Exclude
+	fs.ExcludeNames = c.Exclude
+	tickets.ConfigDirs = c.TicketsDirs
+	return nil
+}
+
+// baseBranch returns base, or the configured base branch when base is
+// empty or configuredBase.
+func baseBranch(base string) string {
+	if base == "" || base == configuredBase {
+		return cfg.Base
+	}
+	return base
+}
+
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the repotools configuration",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "show",
+		Short: "Print the effective config and where each value came from",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg.Write(cmd.OutOrStdout())
+		},
+	})
+	return cmd
+}
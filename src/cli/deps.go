@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"repotools/src/deps"
+
+	"github.com/spf13/cobra"
+)
+
+func newDepsCmd() *cobra.Command {
+	var opts deps.Options
+
+	cmd := &cobra.Command{
+		Use:   "deps [dir]",
+		Short: "Show the internal Go package import graph and cycles",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) > 0 {
+				dir = args[0]
+			}
+			return deps.RunDeps(cmd.OutOrStdout(), dir, opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Importers, "importers", "i", "", "List packages importing this package")
+	cmd.Flags().BoolVarP(&opts.Transitive, "transitive", "T", false, "With --importers, include indirect importers")
+	cmd.Flags().BoolVarP(&opts.External, "external", "x", false, "Also list imports from outside the module")
+	cmd.Flags().BoolVarP(&opts.Tests, "tests", "t", false, "Include imports from _test.go files")
+	cmd.Flags().BoolVar(&opts.DOT, "dot", false, "Output Graphviz DOT")
+	return cmd
+}
@@ -1,7 +1,11 @@
 package cli
 
 import (
+	"fmt"
+	"io"
+
 	"repotools/src/git"
+	"repotools/src/metrics"
 	"repotools/src/runner"
 
 	"github.com/spf13/cobra"
@@ -9,12 +13,27 @@ import (
 
 func newDiffCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:                "diff [base] [flags...]",
-		Aliases:            []string{"di"},
-		Short:              "Diff vs base branch",
+		Use:     "diff [base] [--json|--pager] [--stat-first] [--files GLOB] [--exclude GLOB] [--hunk FILE:N] [flags...]",
+		Aliases: []string{"di"},
+		Short:   "Diff vs base branch",
+		Long: `Diff vs base branch. Hunk headers name the enclosing function, found with
+the fn-spans patterns.
+
+  --stat-first     print a per-file summary before the patches
+  --files GLOB     only files matching GLOB (base name unless it has a /)
+  --exclude GLOB   skip files matching GLOB
+  --hunk FILE:N    only the Nth hunk of FILE
+  --json           print files, hunks and lines as JSON
+  --pager          run git diff directly, with its pager and colors
+
+Other flags are passed to git diff.`,
 		DisableFlagParsing: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			base := "master"
+			opts, args, err := takeArgs(args, []string{"json", "pager", "stat-first"}, []string{"files", "exclude", "hunk"})
+			if err != nil {
+				return err
+			}
+			base := baseBranch("")
 			extra := args
 			if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
 				base = args[0]
@@ -24,8 +43,45 @@ func newDiffCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			gitArgs := append([]string{"git", "diff", mb + "..HEAD"}, extra...)
-			return runner.Exec(gitArgs)
+			diffArgs := append([]string{mb + "..HEAD"}, extra...)
+			if opts["pager"] != "" {
+				return runner.ExecTo(cmd.OutOrStdout(), append([]string{"git", "diff"}, diffArgs...))
+			}
+			patch, err := git.Diff(diffArgs...)
+			if err != nil {
+				return err
+			}
+
+			files := git.ParseDiff(patch)
+			if len(files) == 0 {
+				// Not a patch (--stat, --name-only, ...): print as is.
+				_, err := io.WriteString(cmd.OutOrStdout(), patch)
+				return err
+			}
+			// Summaries git prints ahead of the patches (--patch-with-stat,
+			// --summary -p) are kept, but describe the unfiltered diff.
+			preamble := git.DiffPreamble(patch)
+			if preamble != "" {
+				for _, o := range []string{"json", "stat-first", "files", "exclude", "hunk"} {
+					if opts[o] != "" {
+						return fmt.Errorf("--%s cannot be combined with git's --stat or --summary output", o)
+					}
+				}
+			}
+			files = git.FilterDiff(files, opts["files"], opts["exclude"])
+			if opts["hunk"] != "" {
+				if files, err = git.SelectHunk(files, opts["hunk"]); err != nil {
+					return err
+				}
+			}
+			if err := metrics.LabelHunks(files, "HEAD"); err != nil {
+				return err
+			}
+			io.WriteString(cmd.OutOrStdout(), preamble)
+			return git.WriteDiff(cmd.OutOrStdout(), files, git.DiffOptions{
+				JSON:      opts["json"] != "",
+				StatFirst: opts["stat-first"] != "",
+			})
 		},
 	}
 	return cmd
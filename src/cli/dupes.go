@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"repotools/src/metrics"
+
+	"github.com/spf13/cobra"
+)
+
+func newDupesCmd() *cobra.Command {
+	var opts metrics.DupesOptions
+
+	cmd := &cobra.Command{
+		Use:     "dupes paths...",
+		Aliases: []string{"dp"},
+		Short:   "Find duplicated blocks of code",
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return metrics.RunDupes(cmd.OutOrStdout(), args, opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Glob, "glob", "g", "", "Include files matching glob")
+	cmd.Flags().StringVarP(&opts.Exclude, "exclude", "e", "", "Exclude files with paths matching regex")
+	cmd.Flags().IntVarP(&opts.MinLines, "min-lines", "m", 6, "Smallest duplicate to report, in non-blank code lines")
+	cmd.Flags().BoolVarP(&opts.Identifiers, "identifiers", "i", false, "Ignore identifier names (find renamed copies)")
+	cmd.Flags().IntVarP(&opts.Top, "top", "n", 0, "Show only the N groups with the most duplicated lines")
+	return cmd
+}
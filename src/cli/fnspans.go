@@ -1,23 +1,27 @@
 package cli
 
 import (
-	"os"
-
 	"repotools/src/metrics"
 
 	"github.com/spf13/cobra"
 )
 
 func newFnSpansCmd() *cobra.Command {
-	var glob, excludePath, pattern, after, include, exclude string
+	var glob, excludePath, pattern, after, include, exclude, changed string
 
 	cmd := &cobra.Command{
-		Use:     "fn-spans paths...",
+		Use:     "fn-spans [paths...]",
 		Aliases: []string{"fs"},
 		Short:   "Show function line ranges in source files",
-		Args:    cobra.MinimumNArgs(1),
+		Args:    requirePathsUnlessChanged(&changed),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return metrics.RunFnSpans(os.Stdout, args, glob, excludePath, pattern, after, include, exclude)
+			if changed != "" {
+				return metrics.RunChangedFnSpans(cmd.OutOrStdout(), args, metrics.ChangedOptions{
+					Base: baseBranch(changed), Glob: glob, ExcludePath: excludePath,
+					Pattern: pattern, Include: include, Exclude: exclude,
+				})
+			}
+			return metrics.RunFnSpans(cmd.OutOrStdout(), args, glob, excludePath, pattern, after, include, exclude)
 		},
 	}
 
@@ -27,5 +31,24 @@ func newFnSpansCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&after, "after", "a", "", "Only scan after first line matching this")
 	cmd.Flags().StringVarP(&include, "include", "i", "", "Only include functions matching regex")
 	cmd.Flags().StringVarP(&exclude, "exclude", "x", "", "Exclude functions matching regex")
+	addChangedFlag(cmd, &changed)
 	return cmd
 }
+
+// addChangedFlag registers --changed[=base], which limits a metrics command
+// to the functions touched since the merge base with base.
+func addChangedFlag(cmd *cobra.Command, changed *string) {
+	cmd.Flags().StringVar(changed, "changed", "", "Only functions changed since the merge base with this branch (default: the configured base)")
+	cmd.Flags().Lookup("changed").NoOptDefVal = configuredBase
+}
+
+// requirePathsUnlessChanged requires at least one path unless --changed is
+// set, in which case the whole diff is used.
+func requirePathsUnlessChanged(changed *string) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if *changed != "" {
+			return nil
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	}
+}
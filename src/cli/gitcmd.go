@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// takeArgs removes our own --flags from the arguments of a command that
+// passes the rest on to git. Flags named in bools are set to "true"; those
+// in values take "--name=v" or "--name v". Arguments after "--" are left
+// alone.
+func takeArgs(args []string, bools, values []string) (map[string]string, []string, error) {
+	set := make(map[string]string)
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--" {
+			return set, append(rest, args[i:]...), nil
+		}
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(a, "--"), "=")
+		switch {
+		case !strings.HasPrefix(a, "--"):
+			rest = append(rest, a)
+		case slices.Contains(bools, name) && !hasValue:
+			set[name] = "true"
+		case slices.Contains(values, name):
+			if !hasValue {
+				if i+1 >= len(args) {
+					return nil, nil, fmt.Errorf("flag needs an argument: --%s", name)
+				}
+				i++
+				value = args[i]
+			}
+			set[name] = value
+		default:
+			rest = append(rest, a)
+		}
+	}
+	return set, rest, nil
+}
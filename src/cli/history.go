@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"repotools/src/git"
+	"repotools/src/metrics"
+
+	"github.com/spf13/cobra"
+)
+
+func newHistoryCmd() *cobra.Command {
+	var fn string
+	var count, patchLines int
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "history <file>",
+		Short: "Commits touching a file (following renames), or one function with --fn",
+		Long: `List the commits touching a file, newest first and following renames, with
+lines added and deleted and the file's name when it was different.
+
+--fn NAME finds the function's current span with the fn-spans patterns and
+shows how those lines evolved with git log -L, each commit followed by its
+patch cut to --patch-lines lines. Name a Go method as Type.Method when
+several types have one by that name.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := args[0]
+			var entries []git.HistoryEntry
+			var more bool
+			var err error
+			if fn != "" {
+				span, ferr := metrics.FindFnSpan(path, fn)
+				if ferr != nil {
+					return ferr
+				}
+				entries, more, err = git.FnHistory(path, span.Start, span.End, count)
+			} else {
+				entries, more, err = git.FileHistory(path, count)
+			}
+			if err != nil {
+				return err
+			}
+			return git.WriteHistory(cmd.OutOrStdout(), git.RootPath(path), entries, more, patchLines, asJSON)
+		},
+	}
+
+	cmd.Flags().StringVar(&fn, "fn", "", "Show the history of the function NAME in the file")
+	cmd.Flags().IntVarP(&count, "commits", "n", 20, "Show at most N commits (0 for all)")
+	cmd.Flags().IntVar(&patchLines, "patch-lines", 40, "Cut each --fn patch to N lines (0 for no limit)")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print commits as JSON")
+	return cmd
+}
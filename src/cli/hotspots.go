@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"repotools/src/metrics"
+
+	"github.com/spf13/cobra"
+)
+
+func newHotspotsCmd() *cobra.Command {
+	var opts metrics.HotspotOptions
+
+	cmd := &cobra.Command{
+		Use:     "hotspots [paths...]",
+		Aliases: []string{"hs"},
+		Short:   "Rank files or functions by git churn times size",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return metrics.RunHotspots(cmd.OutOrStdout(), args, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Since, "since", "90d", "History window: Nd, Nw, Nm, Ny or a date (empty for all history)")
+	cmd.Flags().StringVarP(&opts.Glob, "glob", "g", "", "Include files matching glob")
+	cmd.Flags().StringVarP(&opts.Exclude, "exclude", "e", "", "Exclude files with paths matching regex")
+	cmd.Flags().BoolVarP(&opts.Complexity, "complexity", "c", false, "Size by cyclomatic complexity instead of LOC")
+	cmd.Flags().BoolVarP(&opts.Functions, "functions", "f", false, "Attribute churn to functions via fn-spans")
+	cmd.Flags().IntVarP(&opts.Top, "top", "n", 20, "Show only the top N (0 for all)")
+	return cmd
+}
@@ -1,28 +1,38 @@
 package cli
 
 import (
-	"os"
-
 	"repotools/src/metrics"
 
 	"github.com/spf13/cobra"
 )
 
 func newLocCmd() *cobra.Command {
-	var glob, exclude, marker string
+	var opts metrics.LOCOptions
+	var changed string
 
 	cmd := &cobra.Command{
-		Use:     "loc paths...",
+		Use:     "loc [paths...]",
 		Aliases: []string{"lo"},
-		Short:   "Count non-test lines of code per file",
-		Args:    cobra.MinimumNArgs(1),
+		Short:   "Count code, comment, blank and test lines per file and language",
+		Args:    requirePathsUnlessChanged(&changed),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return metrics.RunLOC(os.Stdout, args, glob, exclude, marker)
+			if changed != "" {
+				return metrics.RunChangedLOC(cmd.OutOrStdout(), args, metrics.ChangedOptions{
+					Base: baseBranch(changed), Glob: opts.Glob, ExcludePath: opts.Exclude,
+				})
+			}
+			return metrics.RunLOC(cmd.OutOrStdout(), args, opts)
 		},
 	}
 
-	cmd.Flags().StringVarP(&glob, "glob", "g", "", "Include files matching glob")
-	cmd.Flags().StringVarP(&exclude, "exclude", "e", "", "Exclude files with paths matching regex")
-	cmd.Flags().StringVarP(&marker, "marker", "m", "", "Regex for start of test code")
+	cmd.Flags().StringVarP(&opts.Glob, "glob", "g", "", "Include files matching glob")
+	cmd.Flags().StringVarP(&opts.Exclude, "exclude", "e", "", "Exclude files with paths matching regex")
+	cmd.Flags().StringVarP(&opts.Marker, "marker", "m", "", "Regex for start of test code")
+	cmd.Flags().BoolVarP(&opts.Simple, "simple", "s", false, "One line count per file up to the test marker (no breakdown)")
+	cmd.Flags().BoolVarP(&opts.TestRatio, "tests", "t", false, "Show production vs test code and test ratio per package")
+	cmd.Flags().StringVar(&opts.By, "by", "", "Group into subtotals by dir, ext or lang")
+	cmd.Flags().IntVar(&opts.Depth, "depth", 0, "Directory levels to show (implies --by dir; 0 for all)")
+	cmd.Flags().IntVarP(&opts.Top, "top", "n", 0, "Show only the N largest files, or groups per level")
+	addChangedFlag(cmd, &changed)
 	return cmd
 }
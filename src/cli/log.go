@@ -8,13 +8,15 @@ import (
 )
 
 func newLogCmd() *cobra.Command {
-	return &cobra.Command{
+	var asJSON, pager bool
+
+	cmd := &cobra.Command{
 		Use:     "log [base]",
 		Aliases: []string{"lg"},
 		Short:   "Commits since diverging from base branch",
 		Args:    cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			base := "master"
+			base := baseBranch("")
 			if len(args) > 0 {
 				base = args[0]
 			}
@@ -22,7 +24,18 @@ func newLogCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			return runner.Exec([]string{"git", "log", "--oneline", mb + "..HEAD"})
+			if pager {
+				return runner.ExecTo(cmd.OutOrStdout(), []string{"git", "log", "--oneline", mb + "..HEAD"})
+			}
+			commits, err := git.Log(mb + "..HEAD")
+			if err != nil {
+				return err
+			}
+			return git.WriteLog(cmd.OutOrStdout(), commits, asJSON)
 		},
 	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print commits as JSON")
+	cmd.Flags().BoolVar(&pager, "pager", false, "Run git log directly, with its pager and colors")
+	return cmd
 }
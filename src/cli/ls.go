@@ -1,6 +1,9 @@
 package cli
 
 import (
+	"fmt"
+	"strings"
+
 	"repotools/src/git"
 	"repotools/src/runner"
 
@@ -9,11 +12,16 @@ import (
 
 func newLsCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:                "ls [base] [-- path...]",
+		Use:                "ls [base] [--json|--pager] [-- path...]",
 		Short:              "List files at merge base",
+		Long:               "List files at merge base. Other flags are passed to git ls-tree; -l adds mode, type, hash and size, --json prints them per entry, --pager runs git directly. --format and --object-only print git's own output.",
 		DisableFlagParsing: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			base := "master"
+			opts, args, err := takeArgs(args, []string{"json", "pager"}, nil)
+			if err != nil {
+				return err
+			}
+			base := baseBranch("")
 			extra := args
 			if len(args) > 0 && args[0] != "" && args[0] != "--" && args[0][0] != '-' {
 				base = args[0]
@@ -23,9 +31,35 @@ func newLsCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			gitArgs := append([]string{"git", "ls-tree", "--name-only", mb}, extra...)
-			return runner.Exec(gitArgs)
+			if opts["pager"] != "" {
+				return runner.ExecTo(cmd.OutOrStdout(), append([]string{"git", "ls-tree", "--name-only", mb}, extra...))
+			}
+			if rawTreeArgs(extra) {
+				if opts["json"] != "" {
+					return fmt.Errorf("--json cannot be combined with --format or --object-only")
+				}
+				return runner.ExecTo(cmd.OutOrStdout(), append([]string{"git", "ls-tree", mb}, extra...))
+			}
+			entries, err := git.LsTree(mb, extra...)
+			if err != nil {
+				return err
+			}
+			return git.WriteTree(cmd.OutOrStdout(), entries, opts["json"] != "")
 		},
 	}
 	return cmd
 }
+
+// rawTreeArgs reports whether args ask ls-tree for output WriteTree does not
+// model, which is then printed as git gives it.
+func rawTreeArgs(args []string) bool {
+	for _, a := range args {
+		if a == "--" {
+			break
+		}
+		if a == "--object-only" || strings.HasPrefix(a, "--format") {
+			return true
+		}
+	}
+	return false
+}
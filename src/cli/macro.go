@@ -0,0 +1,195 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"repotools/src/config"
+	"repotools/src/git"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const macroGroup = "macros"
+
+// maxMacroDepth bounds macros running macros, so a macro that calls itself
+// fails instead of recursing forever.
+const maxMacroDepth = 8
+
+var macroDepth int
+
+// Execute runs the command line in os.Args.
+func Execute() error {
+	return execute(NewRootCmd(), os.Args[1:])
+}
+
+// execute runs args with root. The configured aliases and macros are only
+// registered, from the directory -C and -W select, when args do not name a
+// built-in command or ask for the root help, which lists them.
+func execute(root *cobra.Command, args []string) error {
+	name, dir, wt := scanGlobalArgs(root, args)
+	if name == "" || name == "help" {
+		addMacros(root, dir, wt)
+	} else if c, _, err := root.Find([]string{name}); err != nil || c == root {
+		addMacros(root, dir, wt)
+	}
+	root.SetArgs(args)
+	return root.Execute()
+}
+
+// scanGlobalArgs returns the command name in args and the -C and -W values
+// given before it.
+func scanGlobalArgs(root *cobra.Command, args []string) (name, dir, wt string) {
+	flags := root.PersistentFlags()
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--" {
+			return "", dir, wt
+		}
+		if !strings.HasPrefix(a, "-") || a == "-" {
+			return a, dir, wt
+		}
+		var f *pflag.Flag
+		var value string
+		var hasValue bool
+		if strings.HasPrefix(a, "--") {
+			var flag string
+			flag, value, hasValue = strings.Cut(a[2:], "=")
+			f = flags.Lookup(flag)
+		} else {
+			f = flags.ShorthandLookup(a[1:2])
+			value, hasValue = strings.TrimPrefix(a[2:], "="), len(a) > 2
+		}
+		if f == nil {
+			continue
+		}
+		if !hasValue && f.NoOptDefVal == "" && i+1 < len(args) {
+			i++
+			value = args[i]
+		}
+		switch f.Name {
+		case "directory":
+			dir = value
+		case "worktree":
+			wt = value
+		}
+	}
+	return "", dir, wt
+}
+
+// newRootWithMacros is NewRootCmd with the aliases and macros of the
+// working directory registered, for listing every command up front.
+func newRootWithMacros() *cobra.Command {
+	root := NewRootCmd()
+	addMacros(root, "", "")
+	return root
+}
+
+// addMacros registers the aliases and macros of the config found in dir,
+// or the worktree wt, (default: the working directory) as commands of root,
+// in their own help group. Names taken by built-in commands are skipped
+// with a warning. A config that fails to load is reported when a command
+// runs, not here.
+func addMacros(root *cobra.Command, dir, wt string) {
+	c, err := loadMacroConfig(dir, wt)
+	if err != nil || len(c.Aliases)+len(c.Macros) == 0 {
+		return
+	}
+	root.AddGroup(&cobra.Group{ID: macroGroup, Title: "Macros:"})
+	add := func(name string, steps []string, alias bool) {
+		if existing, _, err := root.Find([]string{name}); err == nil && existing != root {
+			if macroDepth > 0 {
+				// Already reported by the outermost command tree.
+				return
+			}
+			fmt.Fprintf(os.Stderr, "repotools: %s %q clashes with the %s command; ignored\n", macroKind(alias), name, existing.Name())
+			return
+		}
+		cmd := newMacroCmd(name, steps, alias)
+		budgetCommand(cmd)
+		root.AddCommand(cmd)
+	}
+	for name, line := range c.Aliases {
+		add(name, []string{line}, true)
+	}
+	for name, steps := range c.Macros {
+		add(name, steps, false)
+	}
+}
+
+// loadMacroConfig loads the config as seen from dir and then worktree wt,
+// returning to the working directory afterwards.
+func loadMacroConfig(dir, wt string) (*config.Config, error) {
+	if dir != "" || wt != "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		defer os.Chdir(cwd)
+		if dir != "" {
+			if err := os.Chdir(dir); err != nil {
+				return nil, err
+			}
+		}
+		if wt != "" {
+			w, err := git.FindWorktree(wt)
+			if err != nil {
+				return nil, err
+			}
+			if err := os.Chdir(w.Path); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return config.Load(builtinConfig)
+}
+
+func macroKind(alias bool) string {
+	if alias {
+		return "alias"
+	}
+	return "macro"
+}
+
+func newMacroCmd(name string, steps []string, alias bool) *cobra.Command {
+	return &cobra.Command{
+		Use:                name + " [args...]",
+		Short:              strings.Join(steps, "; "),
+		GroupID:            macroGroup,
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if macroDepth >= maxMacroDepth {
+				return fmt.Errorf("%s: macros nested more than %d deep", name, maxMacroDepth)
+			}
+			macroDepth++
+			defer func() { macroDepth-- }()
+
+			for i, step := range steps {
+				words, err := config.SplitCommand(step)
+				if err != nil {
+					return fmt.Errorf("%s %s: %w", macroKind(alias), name, err)
+				}
+				argv := config.Expand(words, args, alias)
+				if len(steps) > 1 {
+					if i > 0 {
+						fmt.Fprintln(cmd.OutOrStdout())
+					}
+					fmt.Fprintf(cmd.OutOrStdout(), "==> %s <==\n", strings.Join(argv, " "))
+				}
+
+				root := NewRootCmd()
+				root.SetIn(cmd.InOrStdin())
+				root.SetOut(cmd.OutOrStdout())
+				root.SetErr(cmd.ErrOrStderr())
+				root.SilenceUsage = true
+				root.SilenceErrors = true
+				if err := execute(root, argv); err != nil {
+					return fmt.Errorf("%s: %w", strings.Join(argv, " "), err)
+				}
+			}
+			return nil
+		},
+	}
+}
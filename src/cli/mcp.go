@@ -0,0 +1,25 @@
+package cli
+
+import (
+	"repotools/src/mcp"
+
+	"github.com/spf13/cobra"
+)
+
+func newMCPCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:         "mcp",
+		Short:       "Serve repotools commands as Model Context Protocol tools over stdio",
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{mcp.SkipAnnotation: "true", streamAnnotation: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			server := &mcp.Server{
+				Name:    "repotools",
+				Version: "dev",
+				Tools:   mcp.CommandTools(newRootWithMacros()),
+				Call:    mcp.CommandCaller(newRootWithMacros),
+			}
+			return server.Serve(cmd.InOrStdin(), cmd.OutOrStdout())
+		},
+	}
+}
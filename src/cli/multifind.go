@@ -35,7 +35,7 @@ func newMultiFindCmd() *cobra.Command {
 				return fmt.Errorf("no valid paths found")
 			}
 
-			fs.MultiFind(os.Stdout, headCount, findOpts, paths)
+			fs.MultiFind(cmd.OutOrStdout(), headCount, findOpts, paths)
 			return nil
 		},
 	}
@@ -1,8 +1,6 @@
 package cli
 
 import (
-	"os"
-
 	"repotools/src/fs"
 
 	"github.com/spf13/cobra"
@@ -14,8 +12,10 @@ func newMultiLSCmd() *cobra.Command {
 		Aliases: []string{"ml"},
 		Short:   "List contents of multiple directories",
 		Args:    cobra.MinimumNArgs(1),
+		// Each directory gets a fair share of the budget.
+		Annotations: map[string]string{selfBudgetAnnotation: "true"},
 		Run: func(cmd *cobra.Command, args []string) {
-			fs.MultiLS(os.Stdout, args)
+			fs.MultiLSBudget(cmd.OutOrStdout(), args, budget)
 		},
 	}
 }
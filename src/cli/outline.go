@@ -0,0 +1,25 @@
+package cli
+
+import (
+	"repotools/src/metrics"
+
+	"github.com/spf13/cobra"
+)
+
+func newOutlineCmd() *cobra.Command {
+	var glob, excludePath string
+
+	cmd := &cobra.Command{
+		Use:     "outline paths...",
+		Aliases: []string{"ol"},
+		Short:   "List types, functions, methods and constants with line ranges",
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return metrics.RunOutline(cmd.OutOrStdout(), args, glob, excludePath)
+		},
+	}
+
+	cmd.Flags().StringVarP(&glob, "glob", "g", "", "Include files matching glob")
+	cmd.Flags().StringVarP(&excludePath, "exclude-path", "E", "", "Exclude files with paths matching regex")
+	return cmd
+}
@@ -2,27 +2,29 @@ package cli
 
 import (
 	"fmt"
-	"os"
 
 	"repotools/src/github"
+	"repotools/src/tickets"
 
 	"github.com/spf13/cobra"
 )
 
 func newPRCmd() *cobra.Command {
 	var only, exclude string
+	var showTickets bool
 
 	cmd := &cobra.Command{
-		Use:   "pr [number]",
-		Short: "Show PR info, comments, reviews, checks, files, commits",
-		Args:  cobra.MaximumNArgs(1),
+		Use:         "pr [number]",
+		Short:       "Show PR info, comments, reviews, checks, files, commits",
+		Args:        cobra.MaximumNArgs(1),
+		Annotations: map[string]string{selfBudgetAnnotation: "true"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			prArg := ""
 			if len(args) > 0 {
 				prArg = args[0]
 			}
 
-			sections := github.AllSections
+			sections := cfg.PRSections
 			if only != "" {
 				validated, err := github.ValidateSections(only)
 				if err != nil {
@@ -51,12 +53,28 @@ func newPRCmd() *cobra.Command {
 				}
 			}
 
-			fmt.Fprintln(os.Stdout, github.RenderPR(*data, sections, reviewComments))
+			// The tickets are charged to the budget up front; the sections
+			// share the rest.
+			ticketsText := ""
+			if showTickets {
+				items, err := tickets.LoadItems(nil, "")
+				if err != nil {
+					return err
+				}
+				texts := []string{data.Title, data.Body, data.HeadRefName}
+				for _, c := range data.Commits {
+					texts = append(texts, c.MessageHeadline)
+				}
+				ticketsText = fmt.Sprintf("\n## Tickets\n\n%s\n", tickets.RenderMentions(tickets.Mentioned(items, texts...)))
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), github.RenderPRBudget(*data, sections, reviewComments, budget.Without(ticketsText+"\n")))
+			fmt.Fprint(cmd.OutOrStdout(), ticketsText)
 			return nil
 		},
 	}
 
 	cmd.Flags().StringVar(&only, "only", "", "Comma-separated sections to show")
 	cmd.Flags().StringVar(&exclude, "exclude", "", "Comma-separated sections to hide")
+	cmd.Flags().BoolVarP(&showTickets, "tickets", "t", false, "Show tickets referenced by the PR title, body, branch and commits")
 	return cmd
 }
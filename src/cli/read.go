@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"os"
 	"strconv"
 
 	"repotools/src/fs"
@@ -32,7 +31,7 @@ func newReadCmd() *cobra.Command {
 					return err
 				}
 			}
-			return fs.ReadLines(os.Stdout, path, start, end)
+			return fs.ReadLines(cmd.OutOrStdout(), path, start, end)
 		},
 	}
 }
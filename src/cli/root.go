@@ -1,22 +1,45 @@
 package cli
 
 import (
+	"fmt"
 	"os"
+	"strings"
+
+	"repotools/src/git"
 
 	"github.com/spf13/cobra"
 )
 
-var directory string
+var directory, worktree string
 
 func NewRootCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "repotools",
 		Short: "Repo helper toolkit: git, GitHub, and filesystem operations",
+		// Parse global flags before the command name here, so that commands
+		// parsing their own flags only see the arguments after it.
+		TraverseChildren: true,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.DisableFlagParsing {
+				if _, err := takeGlobalArgs(cmd, args); err != nil {
+					return err
+				}
+			}
 			if directory != "" {
-				return os.Chdir(directory)
+				if err := os.Chdir(directory); err != nil {
+					return err
+				}
 			}
-			return nil
+			if worktree != "" {
+				wt, err := git.FindWorktree(worktree)
+				if err != nil {
+					return err
+				}
+				if err := os.Chdir(wt.Path); err != nil {
+					return err
+				}
+			}
+			return loadConfig()
 		},
 		Run: func(cmd *cobra.Command, args []string) {
 			cmd.Help()
@@ -24,6 +47,8 @@ func NewRootCmd() *cobra.Command {
 		},
 	}
 	cmd.PersistentFlags().StringVarP(&directory, "directory", "C", "", "Change to DIR before doing anything")
+	cmd.PersistentFlags().StringVarP(&worktree, "worktree", "W", "", "Change to the worktree of BRANCH (or worktree dir name) after -C")
+	addBudgetFlags(cmd)
 
 	cmd.AddCommand(
 		newStatusCmd(),
@@ -32,12 +57,62 @@ func NewRootCmd() *cobra.Command {
 		newLsCmd(),
 		newPRCmd(),
 		newReadCmd(),
+		newBlameCmd(),
+		newHistoryCmd(),
 		newMultiLSCmd(),
 		newMultiFindCmd(),
 		newLocCmd(),
 		newFnSpansCmd(),
+		newOutlineCmd(),
+		newComplexityCmd(),
+		newHotspotsCmd(),
+		newDupesCmd(),
+		newDepsCmd(),
 		newTkStatusCmd(),
+		newTkCmd(),
+		newBranchesCmd(),
+		newWorktreesCmd(),
+		newConfigCmd(),
+		newMCPCmd(),
 	)
+	applyBudget(cmd)
 
 	return cmd
 }
+
+// takeGlobalArgs applies the root's persistent flags given in long form
+// (--directory, --worktree, --max-lines, ...) in the arguments of a command
+// that parses its own flags, and returns the other arguments. Shorthands
+// are left alone since they clash with git flags such as -C and -W, and so
+// are arguments after "--".
+func takeGlobalArgs(cmd *cobra.Command, args []string) ([]string, error) {
+	flags := cmd.Root().PersistentFlags()
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--" {
+			return append(rest, args[i:]...), nil
+		}
+		if !strings.HasPrefix(a, "--") {
+			rest = append(rest, a)
+			continue
+		}
+		name, value, hasValue := strings.Cut(a[2:], "=")
+		f := flags.Lookup(name)
+		if f == nil {
+			rest = append(rest, a)
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) || args[i+1] == "--" {
+				return nil, fmt.Errorf("flag needs an argument: --%s", name)
+			}
+			i++
+			value = args[i]
+		}
+		if err := f.Value.Set(value); err != nil {
+			return nil, fmt.Errorf("invalid argument %q for --%s: %w", value, name, err)
+		}
+	}
+	return rest, nil
+}
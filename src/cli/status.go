@@ -1,20 +1,38 @@
 package cli
 
 import (
-	"os"
+	"fmt"
 
 	"repotools/src/git"
+	"repotools/src/tickets"
 
 	"github.com/spf13/cobra"
 )
 
 func newStatusCmd() *cobra.Command {
-	return &cobra.Command{
+	var showTickets bool
+
+	cmd := &cobra.Command{
 		Use:     "status",
 		Aliases: []string{"st"},
 		Short:   "Show current branch and working tree status",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return git.Status(os.Stdout)
+			if err := git.Status(cmd.OutOrStdout()); err != nil {
+				return err
+			}
+			if showTickets {
+				items, err := tickets.LoadItems(nil, "")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), "---")
+				fmt.Fprintln(cmd.OutOrStdout(), "Tickets:")
+				fmt.Fprintln(cmd.OutOrStdout(), tickets.RenderMentions(tickets.Mentioned(items, tickets.BranchTexts(baseBranch(""))...)))
+			}
+			return nil
 		},
 	}
+
+	cmd.Flags().BoolVarP(&showTickets, "tickets", "t", false, "Show tickets referenced by the branch name and its commits")
+	return cmd
 }
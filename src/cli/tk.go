@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"repotools/src/tickets"
+
+	"github.com/spf13/cobra"
+)
+
+func newTkCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tk",
+		Short: "Ticket history and progress commands",
+	}
+	cmd.AddCommand(
+		newTkHistoryCmd(),
+		newTkBurndownCmd(),
+		newTkLinksCmd(),
+	)
+	return cmd
+}
+
+func newTkHistoryCmd() *cobra.Command {
+	var dirs []string
+	var backend string
+
+	cmd := &cobra.Command{
+		Use:   "history [id...]",
+		Short: "Show when tickets were created, changed status and closed (from git log)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return tickets.RunTicketHistory(cmd.OutOrStdout(), dirs, backend, args)
+		},
+	}
+
+	addTicketSourceFlags(cmd, &dirs, &backend)
+	return cmd
+}
+
+func newTkBurndownCmd() *cobra.Command {
+	var dirs []string
+	var backend, since, epic string
+	var weekly bool
+
+	cmd := &cobra.Command{
+		Use:   "burndown",
+		Short: "Chart open/closed ticket counts per day or week",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return tickets.RunTicketBurndown(cmd.OutOrStdout(), dirs, backend, since, epic, weekly)
+		},
+	}
+
+	addTicketSourceFlags(cmd, &dirs, &backend)
+	cmd.Flags().StringVar(&since, "since", "", "Start date: YYYY-MM-DD, Nd or Nw (default: first ticket)")
+	cmd.Flags().StringVar(&epic, "epic", "", "Only count tickets under this epic")
+	cmd.Flags().BoolVarP(&weekly, "weekly", "w", false, "Bucket by week instead of day")
+	return cmd
+}
+
+func newTkLinksCmd() *cobra.Command {
+	var dirs []string
+	var backend string
+
+	cmd := &cobra.Command{
+		Use:   "links <id>",
+		Short: "Find commits, branches and PRs that mention a ticket",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return tickets.RunTicketLinks(cmd.OutOrStdout(), dirs, backend, args[0])
+		},
+	}
+
+	addTicketSourceFlags(cmd, &dirs, &backend)
+	return cmd
+}
@@ -1,20 +1,40 @@
 package cli
 
 import (
-	"os"
-
 	"repotools/src/tickets"
 
 	"github.com/spf13/cobra"
 )
 
 func newTkStatusCmd() *cobra.Command {
-	return &cobra.Command{
+	var dirs []string
+	var backend, format string
+	var noOrphans bool
+	opts := tickets.DefaultStatusOptions()
+
+	cmd := &cobra.Command{
 		Use:     "tk-status",
 		Aliases: []string{"ts"},
 		Short:   "Print ticket project status report to stdout",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return tickets.RunTicketStatus(os.Stdout)
+			opts.HideOrphans = noOrphans
+			return tickets.RunTicketStatus(cmd.OutOrStdout(), dirs, backend, opts, format)
 		},
 	}
+
+	addTicketSourceFlags(cmd, &dirs, &backend)
+	cmd.Flags().IntSliceVarP(&opts.Priorities, "priority", "p", nil, "Only show these priorities (e.g. 0,1)")
+	cmd.Flags().StringVarP(&opts.Epic, "epic", "e", "", "Only show this epic's subtree")
+	cmd.Flags().BoolVar(&noOrphans, "no-orphans", false, "Hide the orphaned tickets section")
+	cmd.Flags().IntVar(&opts.Depth, "depth", opts.Depth, "Levels of sub-epics to show (-1 for all)")
+	cmd.Flags().BoolVar(&opts.Percent, "percent", false, "Show percentage complete per epic")
+	cmd.Flags().StringVarP(&format, "format", "f", tickets.FormatText, "Output format: text, markdown or json")
+	return cmd
+}
+
+// addTicketSourceFlags registers the flags every ticket command uses to pick
+// its backend.
+func addTicketSourceFlags(cmd *cobra.Command, dirs *[]string, backend *string) {
+	cmd.Flags().StringArrayVarP(dirs, "dir", "d", nil, "Tickets dir or beads file (repeatable; default: $"+tickets.EnvTicketsDir+", git config "+tickets.ConfigTicketsDir+", or nearest .tickets/ or .beads/)")
+	cmd.Flags().StringVarP(backend, "backend", "b", "", "Ticket backend: "+tickets.KindMarkdown+" or "+tickets.KindBeads+" (default: auto-detect)")
 }
@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"repotools/src/git"
+
+	"github.com/spf13/cobra"
+)
+
+func newWorktreesCmd() *cobra.Command {
+	var base string
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:     "worktrees",
+		Aliases: []string{"wt"},
+		Short:   "List worktrees with branch, dirty state, ahead/behind base and last commit",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			statuses, err := git.WorktreeStatuses(baseBranch(base))
+			if err != nil {
+				return err
+			}
+			return git.WriteWorktrees(cmd.OutOrStdout(), statuses, asJSON)
+		},
+	}
+
+	cmd.Flags().StringVarP(&base, "base", "b", "", "Branch to count commits ahead and behind of (default: the configured base)")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print worktrees as JSON")
+	return cmd
+}
@@ -0,0 +1,292 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"repotools/src/git"
+)
+
+// FileName is the per-repository config file, read from the git root.
+const FileName = ".repotools.toml"
+
+// SourceDefault is the source of a value nothing overrides.
+const SourceDefault = "default"
+
+// Config holds per-repository defaults. Later files override earlier ones
+// key by key; the map tables override per extension.
+type Config struct {
+	// Base is the branch log, diff, ls and --changed compare against.
+	Base string
+	// TicketsDirs are ticket directories, relative to the git root.
+	TicketsDirs []string
+	// Exclude are file and directory name globs skipped when walking and
+	// by multi-find.
+	Exclude []string
+	// PRSections are the pr sections shown without --only.
+	PRSections []string
+	// TestMarkers maps a file extension to the regex starting inline tests.
+	TestMarkers map[string]string
+	// FnPatterns maps a file extension to the function definition regex.
+	FnPatterns map[string]string
+	// Aliases map a command name to a repotools command line; arguments
+	// are appended unless it uses placeholders.
+	Aliases map[string]string
+	// Macros map a command name to repotools command lines run in order,
+	// with {1}..{9} replaced by the arguments and {*} by all of them.
+	Macros map[string][]string
+
+	// Files are the config files that were read, in order.
+	Files []string
+	// Sources maps each key ("base", "pr.sections", `test_markers.".rs"`)
+	// to the file that set it, or SourceDefault.
+	Sources map[string]string
+}
+
+// UserPath returns the user-wide config file, in the OS config directory.
+func UserPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "repotools", "config.toml"), nil
+}
+
+// RepoPath returns the config file at the root of the current repository,
+// or "" outside one.
+func RepoPath() string {
+	root, err := git.Toplevel()
+	if err != nil || root == "" {
+		return ""
+	}
+	return filepath.Join(root, FileName)
+}
+
+// Load merges the user config and then the repository config over
+// defaults. Missing files are skipped.
+func Load(defaults Config) (*Config, error) {
+	var paths []string
+	if p, err := UserPath(); err == nil {
+		paths = append(paths, p)
+	}
+	if p := RepoPath(); p != "" {
+		paths = append(paths, p)
+	}
+	return LoadFiles(defaults, paths...)
+}
+
+// LoadFiles merges the given files, in order, over defaults. Missing files
+// are skipped.
+func LoadFiles(defaults Config, paths ...string) (*Config, error) {
+	c := defaults.clone()
+	for _, p := range paths {
+		f, err := os.Open(p)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		err = c.read(f, p)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		c.Files = append(c.Files, p)
+	}
+	return c, nil
+}
+
+// clone copies defaults and marks every value as coming from them.
+func (d Config) clone() *Config {
+	c := &Config{
+		Base:        d.Base,
+		TicketsDirs: slices.Clone(d.TicketsDirs),
+		Exclude:     slices.Clone(d.Exclude),
+		PRSections:  slices.Clone(d.PRSections),
+		TestMarkers: maps.Clone(d.TestMarkers),
+		FnPatterns:  maps.Clone(d.FnPatterns),
+		Aliases:     maps.Clone(d.Aliases),
+		Macros:      maps.Clone(d.Macros),
+		Sources:     make(map[string]string),
+	}
+	if c.TestMarkers == nil {
+		c.TestMarkers = make(map[string]string)
+	}
+	if c.FnPatterns == nil {
+		c.FnPatterns = make(map[string]string)
+	}
+	if c.Aliases == nil {
+		c.Aliases = make(map[string]string)
+	}
+	if c.Macros == nil {
+		c.Macros = make(map[string][]string)
+	}
+	for _, k := range []string{"base", "exclude", "tickets.dirs", "pr.sections"} {
+		c.Sources[k] = SourceDefault
+	}
+	for ext := range c.TestMarkers {
+		c.Sources[mapKey("test_markers", ext)] = SourceDefault
+	}
+	for ext := range c.FnPatterns {
+		c.Sources[mapKey("fn_patterns", ext)] = SourceDefault
+	}
+	for name := range c.Aliases {
+		c.Sources[mapKey("aliases", name)] = SourceDefault
+	}
+	for name := range c.Macros {
+		c.Sources[mapKey("macros", name)] = SourceDefault
+	}
+	return c
+}
+
+func (c *Config) read(r io.Reader, source string) error {
+	entries, err := parse(r)
+	if err != nil {
+		return fmt.Errorf("%s: %w", source, err)
+	}
+	for _, e := range entries {
+		if err := c.set(e, source); err != nil {
+			return fmt.Errorf("%s:%d: %w", source, e.Line, err)
+		}
+	}
+	return nil
+}
+
+func (c *Config) set(e entry, source string) error {
+	str := func(dst *string) error {
+		if e.Value.IsList {
+			return fmt.Errorf("%s must be a string", e.Key)
+		}
+		*dst = e.Value.Str
+		return nil
+	}
+	list := func(dst *[]string) error {
+		if !e.Value.IsList {
+			return fmt.Errorf("%s must be an array of strings", e.Key)
+		}
+		*dst = e.Value.List
+		return nil
+	}
+
+	var err error
+	key := e.Key
+	switch e.Table {
+	case "":
+		switch e.Key {
+		case "base":
+			err = str(&c.Base)
+		case "exclude":
+			err = list(&c.Exclude)
+		default:
+			return fmt.Errorf("unknown key %q", e.Key)
+		}
+	case "tickets":
+		if e.Key != "dirs" {
+			return fmt.Errorf("unknown key %q in [tickets]", e.Key)
+		}
+		key = "tickets.dirs"
+		err = list(&c.TicketsDirs)
+	case "pr":
+		if e.Key != "sections" {
+			return fmt.Errorf("unknown key %q in [pr]", e.Key)
+		}
+		key = "pr.sections"
+		err = list(&c.PRSections)
+	case "test_markers", "fn_patterns":
+		var pattern string
+		if err = str(&pattern); err == nil {
+			if e.Table == "test_markers" {
+				c.TestMarkers[e.Key] = pattern
+			} else {
+				c.FnPatterns[e.Key] = pattern
+			}
+		}
+		key = mapKey(e.Table, e.Key)
+	case "aliases":
+		var line string
+		if err = str(&line); err == nil {
+			c.Aliases[e.Key] = line
+		}
+		key = mapKey(e.Table, e.Key)
+	case "macros":
+		var steps []string
+		if err = list(&steps); err == nil {
+			c.Macros[e.Key] = steps
+		}
+		key = mapKey(e.Table, e.Key)
+	default:
+		return fmt.Errorf("unknown table [%s]", e.Table)
+	}
+	if err != nil {
+		return err
+	}
+	c.Sources[key] = source
+	return nil
+}
+
+func mapKey(table, ext string) string {
+	return table + "." + quote(ext)
+}
+
+// Write prints the config as TOML, each value followed by a comment naming
+// where it came from.
+func (c *Config) Write(w io.Writer) {
+	for _, f := range c.Files {
+		fmt.Fprintf(w, "# read %s\n", f)
+	}
+	if len(c.Files) > 0 {
+		fmt.Fprintln(w)
+	}
+	line := func(key, val, source string) {
+		fmt.Fprintf(w, "%s = %s  # %s\n", key, val, source)
+	}
+	line("base", quote(c.Base), c.Sources["base"])
+	line("exclude", quoteList(c.Exclude), c.Sources["exclude"])
+
+	fmt.Fprintln(w, "\n[tickets]")
+	line("dirs", quoteList(c.TicketsDirs), c.Sources["tickets.dirs"])
+
+	fmt.Fprintln(w, "\n[pr]")
+	line("sections", quoteList(c.PRSections), c.Sources["pr.sections"])
+
+	for _, table := range []struct {
+		name   string
+		values map[string]string
+	}{{"test_markers", c.TestMarkers}, {"fn_patterns", c.FnPatterns}, {"aliases", c.Aliases}} {
+		fmt.Fprintf(w, "\n[%s]\n", table.name)
+		for _, k := range sortedKeys(table.values) {
+			line(quote(k), quote(table.values[k]), c.Sources[mapKey(table.name, k)])
+		}
+	}
+
+	fmt.Fprintln(w, "\n[macros]")
+	for _, name := range sortedKeys(c.Macros) {
+		line(quote(name), quoteList(c.Macros[name]), c.Sources[mapKey("macros", name)])
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func quoteList(items []string) string {
+	quoted := make([]string, len(items))
+	for i, s := range items {
+		quoted[i] = quote(s)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
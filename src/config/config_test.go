@@ -0,0 +1,169 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParse(t *testing.T) {
+	entries, err := parse(strings.NewReader(`
+# comment
+base = "main" # trailing
+exclude = ['gen', "a#b"]
+
+[pr]
+sections = [
+  "info",  # first
+  "checks",
+]
+
+[fn_patterns]
+".rs" = '^\s*fn\s+(\w+)'
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []entry{
+		{"", "base", value{Str: "main"}, 3},
+		{"", "exclude", value{List: []string{"gen", "a#b"}, IsList: true}, 4},
+		{"pr", "sections", value{List: []string{"info", "checks"}, IsList: true}, 7},
+		{"fn_patterns", ".rs", value{Str: `^\s*fn\s+(\w+)`}, 13},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("got %+v\nwant %+v", entries, want)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, input := range []string{
+		"base",
+		"base = main",
+		`base = "main`,
+		`exclude = ["a" "b"]`,
+		"[pr",
+		"bad key = 'x'",
+	} {
+		if _, err := parse(strings.NewReader(input)); err == nil {
+			t.Errorf("parse(%q) succeeded, want error", input)
+		}
+	}
+}
+
+func TestLoadFiles(t *testing.T) {
+	defaults := Config{
+		Base:        "master",
+		PRSections:  []string{"info", "body"},
+		TestMarkers: map[string]string{".rs": "rs"},
+	}
+	user := writeConfig(t, "base = \"develop\"\nexclude = [\"gen\"]\n")
+	repo := writeConfig(t, "base = \"main\"\n[test_markers]\n\".py\" = \"py\"\n")
+
+	c, err := LoadFiles(defaults, user, filepath.Join(t.TempDir(), "missing.toml"), repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Base != "main" || c.Sources["base"] != repo {
+		t.Errorf("base = %q from %q, want main from repo", c.Base, c.Sources["base"])
+	}
+	if !reflect.DeepEqual(c.Exclude, []string{"gen"}) || c.Sources["exclude"] != user {
+		t.Errorf("exclude = %v from %q, want [gen] from user", c.Exclude, c.Sources["exclude"])
+	}
+	if c.TestMarkers[".rs"] != "rs" || c.TestMarkers[".py"] != "py" {
+		t.Errorf("test markers = %v, want merged .rs and .py", c.TestMarkers)
+	}
+	if c.Sources["pr.sections"] != SourceDefault {
+		t.Errorf("pr.sections source = %q, want default", c.Sources["pr.sections"])
+	}
+	if !reflect.DeepEqual(c.Files, []string{user, repo}) {
+		t.Errorf("files = %v", c.Files)
+	}
+	if len(defaults.TestMarkers) != 1 {
+		t.Errorf("defaults were modified: %v", defaults.TestMarkers)
+	}
+}
+
+func TestLoadFiles_Invalid(t *testing.T) {
+	for _, content := range []string{
+		"colour = \"red\"\n",
+		"base = [\"main\"]\n",
+		"[pr]\nsections = \"info\"\n",
+		"[nope]\nx = \"y\"\n",
+	} {
+		path := writeConfig(t, content)
+		if _, err := LoadFiles(Config{}, path); err == nil {
+			t.Errorf("%q: want error", content)
+		} else if !strings.Contains(err.Error(), path) {
+			t.Errorf("error should name the file: %v", err)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	repo := writeConfig(t, "[fn_patterns]\n\".go\" = '^func (\\w+)'\n")
+	c, err := LoadFiles(Config{Base: "master", PRSections: []string{"info"}}, repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	c.Write(&buf)
+	out := buf.String()
+	for _, want := range []string{
+		"# read " + repo,
+		`base = "master"  # default`,
+		`sections = ["info"]  # default`,
+		`".go" = '^func (\w+)'  # ` + repo,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+
+	// The output reads back as the same config.
+	again, err := LoadFiles(Config{}, writeConfig(t, out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Base != c.Base || !reflect.DeepEqual(again.FnPatterns, c.FnPatterns) {
+		t.Errorf("round trip changed config: %+v", again)
+	}
+}
+
+func TestLoadFiles_AliasesAndMacros(t *testing.T) {
+	path := writeConfig(t, `
+[aliases]
+checks = "pr --only checks"
+
+[macros]
+review = ["status", "log", "pr {1} --only checks"]
+`)
+	c, err := LoadFiles(Config{}, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Aliases["checks"] != "pr --only checks" {
+		t.Errorf("aliases = %v", c.Aliases)
+	}
+	if want := []string{"status", "log", "pr {1} --only checks"}; !reflect.DeepEqual(c.Macros["review"], want) {
+		t.Errorf("macros = %v", c.Macros)
+	}
+	if c.Sources[`macros."review"`] != path {
+		t.Errorf("sources = %v", c.Sources)
+	}
+
+	if _, err := LoadFiles(Config{}, writeConfig(t, "[macros]\nreview = \"status\"\n")); err == nil {
+		t.Error("macro given as a string: want error")
+	}
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// placeholderRe matches {1}..{9} and {*} in alias and macro steps.
+var placeholderRe = regexp.MustCompile(`\{([1-9]|\*)\}`)
+
+// SplitCommand splits a command line into words, honoring single and
+// double quotes and backslash escapes outside single quotes.
+func SplitCommand(line string) ([]string, error) {
+	var words []string
+	var cur strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, c := range line {
+		switch {
+		case escaped:
+			cur.WriteRune(c)
+			escaped = false
+		case c == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(c)
+			}
+		case c == '"' || c == '\'':
+			quote = c
+			inWord = true
+		case c == ' ' || c == '\t':
+			if inWord {
+				words = append(words, cur.String())
+				cur.Reset()
+				inWord = false
+			}
+		default:
+			cur.WriteRune(c)
+			inWord = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote in %q", line)
+	}
+	if inWord {
+		words = append(words, cur.String())
+	}
+	return words, nil
+}
+
+// Expand substitutes args into the words of a step: a word that is exactly
+// {N} becomes the Nth argument or is dropped when there is none, {*}
+// becomes all arguments, and placeholders inside a word are replaced in
+// place. With appendArgs, a step without placeholders gets the arguments
+// appended, as for an alias.
+func Expand(words, args []string, appendArgs bool) []string {
+	var out []string
+	used := false
+	for _, w := range words {
+		if !placeholderRe.MatchString(w) {
+			out = append(out, w)
+			continue
+		}
+		used = true
+		switch {
+		case w == "{*}":
+			out = append(out, args...)
+		case placeholderRe.FindString(w) == w:
+			if n, _ := strconv.Atoi(w[1:2]); n <= len(args) {
+				out = append(out, args[n-1])
+			}
+		default:
+			out = append(out, placeholderRe.ReplaceAllStringFunc(w, func(p string) string {
+				if p == "{*}" {
+					return strings.Join(args, " ")
+				}
+				if n, _ := strconv.Atoi(p[1:2]); n <= len(args) {
+					return args[n-1]
+				}
+				return ""
+			}))
+		}
+	}
+	if appendArgs && !used {
+		out = append(out, args...)
+	}
+	return out
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"pr --only checks", []string{"pr", "--only", "checks"}},
+		{`  log   main `, []string{"log", "main"}},
+		{`fn-spans -i 'Run.*' "a b"`, []string{"fn-spans", "-i", "Run.*", "a b"}},
+		{`read a\ b ''`, []string{"read", "a b", ""}},
+	}
+	for _, tt := range tests {
+		got, err := SplitCommand(tt.in)
+		if err != nil {
+			t.Errorf("SplitCommand(%q): %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SplitCommand(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+	if _, err := SplitCommand(`pr "open`); err == nil {
+		t.Error("unterminated quote: want error")
+	}
+}
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		words      []string
+		args       []string
+		appendArgs bool
+		want       []string
+	}{
+		{[]string{"pr", "{1}", "--only", "checks"}, []string{"42"}, false, []string{"pr", "42", "--only", "checks"}},
+		{[]string{"pr", "{1}", "--only", "checks"}, nil, false, []string{"pr", "--only", "checks"}},
+		{[]string{"log", "{*}"}, []string{"a", "b"}, false, []string{"log", "a", "b"}},
+		{[]string{"loc", "--by={2}", "{1}"}, []string{"src", "dir"}, false, []string{"loc", "--by=dir", "src"}},
+		{[]string{"status"}, []string{"x"}, false, []string{"status"}},
+		{[]string{"pr", "--only", "checks"}, []string{"42"}, true, []string{"pr", "--only", "checks", "42"}},
+		{[]string{"pr", "{1}"}, []string{"42", "x"}, true, []string{"pr", "42"}},
+	}
+	for _, tt := range tests {
+		if got := Expand(tt.words, tt.args, tt.appendArgs); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Expand(%q, %q, %v) = %q, want %q", tt.words, tt.args, tt.appendArgs, got, tt.want)
+		}
+	}
+}
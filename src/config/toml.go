@@ -0,0 +1,215 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// entry is one key assignment of a config file, under the table declared
+// before it ("" at the top level).
+type entry struct {
+	Table string
+	Key   string
+	Value value
+	Line  int
+}
+
+// value is a TOML string or array of strings, the only types the config
+// uses.
+type value struct {
+	Str    string
+	List   []string
+	IsList bool
+}
+
+// parse reads the TOML subset used by config files: [table] headers,
+// bare or quoted keys, basic and literal strings, arrays of strings (which
+// may span lines) and # comments.
+func parse(r io.Reader) ([]entry, error) {
+	var entries []entry
+	table := ""
+	scanner := bufio.NewScanner(r)
+	n := 0
+	for scanner.Scan() {
+		n++
+		line := strings.TrimSpace(stripComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || strings.HasPrefix(line, "[[") {
+				return nil, fmt.Errorf("line %d: invalid table header %q", n, line)
+			}
+			table = strings.TrimSpace(line[1 : len(line)-1])
+			if table == "" {
+				return nil, fmt.Errorf("line %d: empty table name", n)
+			}
+			continue
+		}
+
+		rawKey, rawValue, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", n)
+		}
+		key, err := parseKey(strings.TrimSpace(rawKey))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		start := n
+		rawValue = strings.TrimSpace(rawValue)
+		// Arrays may continue over several lines until the closing bracket.
+		for strings.HasPrefix(rawValue, "[") && !arrayClosed(rawValue) && scanner.Scan() {
+			n++
+			rawValue += " " + strings.TrimSpace(stripComment(scanner.Text()))
+		}
+		v, err := parseValue(rawValue)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", start, key, err)
+		}
+		entries = append(entries, entry{Table: table, Key: key, Value: v, Line: start})
+	}
+	return entries, scanner.Err()
+}
+
+func parseKey(s string) (string, error) {
+	if s == "" {
+		return "", fmt.Errorf("empty key")
+	}
+	if s[0] == '"' || s[0] == '\'' {
+		k, rest, err := parseString(s)
+		if err != nil {
+			return "", err
+		}
+		if rest != "" {
+			return "", fmt.Errorf("unexpected %q after key", rest)
+		}
+		return k, nil
+	}
+	for _, c := range s {
+		if !(c == '_' || c == '-' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return "", fmt.Errorf("invalid bare key %q (quote it)", s)
+		}
+	}
+	return s, nil
+}
+
+func parseValue(s string) (value, error) {
+	if s == "" {
+		return value{}, fmt.Errorf("missing value")
+	}
+	if s[0] != '[' {
+		str, rest, err := parseString(s)
+		if err != nil {
+			return value{}, err
+		}
+		if rest != "" {
+			return value{}, fmt.Errorf("unexpected %q after value", rest)
+		}
+		return value{Str: str}, nil
+	}
+
+	v := value{IsList: true, List: []string{}}
+	rest := strings.TrimSpace(s[1:])
+	for {
+		if strings.HasPrefix(rest, "]") {
+			if rest = strings.TrimSpace(rest[1:]); rest != "" {
+				return value{}, fmt.Errorf("unexpected %q after array", rest)
+			}
+			return v, nil
+		}
+		item, after, err := parseString(rest)
+		if err != nil {
+			return value{}, err
+		}
+		v.List = append(v.List, item)
+		rest = strings.TrimSpace(after)
+		if strings.HasPrefix(rest, ",") {
+			rest = strings.TrimSpace(rest[1:])
+		} else if !strings.HasPrefix(rest, "]") {
+			return value{}, fmt.Errorf("expected , or ] in array")
+		}
+	}
+}
+
+// parseString reads a leading basic ("...") or literal ('...') string and
+// returns it with the remaining input.
+func parseString(s string) (string, string, error) {
+	if s == "" || (s[0] != '"' && s[0] != '\'') {
+		return "", "", fmt.Errorf("expected a quoted string, got %q", s)
+	}
+	quote := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case quote == '"' && s[i] == '\\':
+			i++
+		case s[i] == quote:
+			if quote == '\'' {
+				return s[1:i], strings.TrimSpace(s[i+1:]), nil
+			}
+			str, err := strconv.Unquote(s[:i+1])
+			if err != nil {
+				return "", "", fmt.Errorf("invalid string %s", s[:i+1])
+			}
+			return str, strings.TrimSpace(s[i+1:]), nil
+		}
+	}
+	return "", "", fmt.Errorf("unterminated string %s", s)
+}
+
+// stripComment drops a # comment that is not inside a string.
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// arrayClosed reports whether the brackets of an array value balance,
+// ignoring brackets inside strings.
+func arrayClosed(s string) bool {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		}
+	}
+	return depth == 0
+}
+
+// quote renders s as a TOML string, using a literal string for values with
+// backslashes so regular expressions read as written.
+func quote(s string) string {
+	if strings.Contains(s, `\`) && !strings.ContainsAny(s, "'\n") {
+		return "'" + s + "'"
+	}
+	return strconv.Quote(s)
+}
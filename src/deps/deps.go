@@ -0,0 +1,300 @@
+package deps
+
+import (
+	"bufio"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Package is one Go package of the module and its imports.
+type Package struct {
+	// Path is the import path relative to the module ("." for the root).
+	Path string
+	// Imports are the module-internal packages imported, as relative paths.
+	Imports []string
+	// External are the imports from outside the module (including std).
+	External []string
+}
+
+// Graph is the internal import graph of a Go module.
+type Graph struct {
+	Module   string
+	Root     string
+	Packages map[string]*Package
+}
+
+// FindModuleRoot returns the nearest directory at or above dir holding a
+// go.mod file.
+func FindModuleRoot(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for d := abs; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, "go.mod")); err == nil {
+			return d, nil
+		}
+		if filepath.Dir(d) == d {
+			return "", fmt.Errorf("no go.mod found at or above %s", abs)
+		}
+	}
+}
+
+// ReadModulePath returns the module path declared in root/go.mod.
+func ReadModulePath(root string) (string, error) {
+	f, err := os.Open(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "module" {
+			if path, err := strconv.Unquote(fields[1]); err == nil {
+				return path, nil
+			}
+			return fields[1], nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("no module line in %s", filepath.Join(root, "go.mod"))
+}
+
+// Load parses the imports of every package under root, skipping vendor,
+// testdata and hidden or underscore-prefixed directories. Test files are
+// included when tests is set.
+func Load(root string, tests bool) (*Graph, error) {
+	module, err := ReadModulePath(root)
+	if err != nil {
+		return nil, err
+	}
+	g := &Graph{Module: module, Root: root, Packages: make(map[string]*Package)}
+	fset := token.NewFileSet()
+
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if path != root && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			if path != root {
+				if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+					// Nested module.
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+		if !strings.HasSuffix(name, ".go") || (!tests && strings.HasSuffix(name, "_test.go")) {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, parser.ImportsOnly)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, filepath.Dir(path))
+		rel = filepath.ToSlash(rel)
+		pkg := g.Packages[rel]
+		if pkg == nil {
+			pkg = &Package{Path: rel}
+			g.Packages[rel] = pkg
+		}
+		for _, imp := range file.Imports {
+			ip, _ := strconv.Unquote(imp.Path.Value)
+			if internal, ok := g.relative(ip); ok {
+				if internal != rel {
+					pkg.Imports = appendUnique(pkg.Imports, internal)
+				}
+			} else {
+				pkg.External = appendUnique(pkg.External, ip)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range g.Packages {
+		sort.Strings(p.Imports)
+		sort.Strings(p.External)
+	}
+	return g, nil
+}
+
+// relative maps a full import path to a module-relative one.
+func (g *Graph) relative(importPath string) (string, bool) {
+	if importPath == g.Module {
+		return ".", true
+	}
+	if rest, ok := strings.CutPrefix(importPath, g.Module+"/"); ok {
+		return rest, true
+	}
+	return "", false
+}
+
+// Resolve accepts a package as a full import path, a module-relative path or
+// a directory and returns its module-relative path.
+func (g *Graph) Resolve(pkg string) (string, error) {
+	candidates := []string{filepath.ToSlash(filepath.Clean(pkg))}
+	if rel, ok := g.relative(pkg); ok {
+		candidates = append([]string{rel}, candidates...)
+	}
+	for _, c := range candidates {
+		if _, ok := g.Packages[c]; ok {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("package %s not found in module %s", pkg, g.Module)
+}
+
+// Sorted returns the package paths in order.
+func (g *Graph) Sorted() []string {
+	paths := make([]string, 0, len(g.Packages))
+	for p := range g.Packages {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// ImportedBy returns the packages importing pkg, directly or, when
+// transitive is set, through other packages.
+func (g *Graph) ImportedBy(pkg string, transitive bool) []string {
+	reverse := make(map[string][]string)
+	for _, p := range g.Packages {
+		for _, imp := range p.Imports {
+			reverse[imp] = append(reverse[imp], p.Path)
+		}
+	}
+	seen := map[string]bool{pkg: true}
+	var out []string
+	queue := []string{pkg}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, importer := range reverse[cur] {
+			if seen[importer] {
+				continue
+			}
+			seen[importer] = true
+			out = append(out, importer)
+			if transitive {
+				queue = append(queue, importer)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Cycles returns one import cycle per strongly connected component of the
+// graph, each as a path starting and ending at its smallest package.
+func (g *Graph) Cycles() [][]string {
+	var (
+		index   = make(map[string]int)
+		low     = make(map[string]int)
+		onStack = make(map[string]bool)
+		stack   []string
+		next    int
+		cycles  [][]string
+	)
+	var strongConnect func(v string)
+	strongConnect = func(v string) {
+		index[v], low[v] = next, next
+		next++
+		stack = append(stack, v)
+		onStack[v] = true
+		for _, w := range g.Packages[v].Imports {
+			if _, ok := g.Packages[w]; !ok {
+				continue
+			}
+			if _, visited := index[w]; !visited {
+				strongConnect(w)
+				low[v] = min(low[v], low[w])
+			} else if onStack[w] {
+				low[v] = min(low[v], index[w])
+			}
+		}
+		if low[v] != index[v] {
+			return
+		}
+		component := make(map[string]bool)
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			component[w] = true
+			if w == v {
+				break
+			}
+		}
+		if len(component) > 1 {
+			cycles = append(cycles, g.cycleWithin(component))
+		}
+	}
+	for _, p := range g.Sorted() {
+		if _, visited := index[p]; !visited {
+			strongConnect(p)
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+	return cycles
+}
+
+// cycleWithin finds a shortest cycle through the smallest package of a
+// strongly connected component.
+func (g *Graph) cycleWithin(component map[string]bool) []string {
+	var start string
+	for p := range component {
+		if start == "" || p < start {
+			start = p
+		}
+	}
+	prev := map[string]string{}
+	queue := []string{start}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, imp := range g.Packages[cur].Imports {
+			if !component[imp] {
+				continue
+			}
+			if imp == start {
+				path := []string{start}
+				for p := cur; p != start; p = prev[p] {
+					path = append([]string{p}, path...)
+				}
+				return append([]string{start}, path...)
+			}
+			if _, seen := prev[imp]; !seen {
+				prev[imp] = cur
+				queue = append(queue, imp)
+			}
+		}
+	}
+	return []string{start}
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, x := range list {
+		if x == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
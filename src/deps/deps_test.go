@@ -0,0 +1,106 @@
+package deps
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func sampleModule(t *testing.T) string {
+	return writeModule(t, map[string]string{
+		"go.mod":          "module example.com/m\n\ngo 1.22\n",
+		"main.go":         "package main\n\nimport (\n\t\"fmt\"\n\t\"example.com/m/a\"\n)\n",
+		"a/a.go":          "package a\n\nimport \"example.com/m/b\"\n",
+		"b/b.go":          "package b\n\nimport \"example.com/m/c\"\n",
+		"c/c.go":          "package c\n\nimport \"example.com/m/a\"\n",
+		"c/c_test.go":     "package c\n\nimport \"example.com/m/d\"\n",
+		"d/d.go":          "package d\n",
+		"testdata/x/x.go": "package x\n\nimport \"example.com/m/a\"\n",
+		"vendor/v/v.go":   "package v\n",
+		"nested/go.mod":   "module example.com/nested\n",
+		"nested/n/n.go":   "package n\n",
+	})
+}
+
+func TestReadModulePath(t *testing.T) {
+	root := writeModule(t, map[string]string{"go.mod": "// comment\nmodule \"example.com/q\"\n"})
+	got, err := ReadModulePath(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "example.com/q" {
+		t.Errorf("ReadModulePath = %q", got)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	g, err := Load(sampleModule(t), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{".", "a", "b", "c", "d"}
+	if got := g.Sorted(); !reflect.DeepEqual(got, want) {
+		t.Errorf("packages = %v, want %v", got, want)
+	}
+	if got := g.Packages["."]; !reflect.DeepEqual(got.Imports, []string{"a"}) || !reflect.DeepEqual(got.External, []string{"fmt"}) {
+		t.Errorf("root = %+v", got)
+	}
+	if got := g.ImportedBy("d", false); len(got) != 0 {
+		t.Errorf("d imported by %v without tests", got)
+	}
+
+	g, err = Load(g.Root, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := g.ImportedBy("d", false); !reflect.DeepEqual(got, []string{"c"}) {
+		t.Errorf("d imported by %v, want [c]", got)
+	}
+}
+
+func TestImportedByAndCycles(t *testing.T) {
+	g, err := Load(sampleModule(t), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := g.ImportedBy("a", false); !reflect.DeepEqual(got, []string{".", "c"}) {
+		t.Errorf("direct importers of a = %v", got)
+	}
+	if got := g.ImportedBy("c", true); !reflect.DeepEqual(got, []string{".", "a", "b"}) {
+		t.Errorf("transitive importers of c = %v", got)
+	}
+	want := [][]string{{"a", "b", "c", "a"}}
+	if got := g.Cycles(); !reflect.DeepEqual(got, want) {
+		t.Errorf("cycles = %v, want %v", got, want)
+	}
+}
+
+func TestRunDeps_DOT(t *testing.T) {
+	root := sampleModule(t)
+	var buf bytes.Buffer
+	if err := RunDeps(&buf, filepath.Join(root, "a"), Options{DOT: true}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{`digraph "example.com/m" {`, `"." -> "a";`, `"c" -> "a" [color=red];`, `"d";`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
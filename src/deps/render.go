@@ -0,0 +1,104 @@
+package deps
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Options shapes the deps output.
+type Options struct {
+	// Importers lists the packages importing this one instead of the graph.
+	Importers string
+	// Transitive includes indirect importers.
+	Transitive bool
+	// External lists imports from outside the module too.
+	External bool
+	Tests    bool
+	DOT      bool
+}
+
+func RunDeps(w io.Writer, dir string, opts Options) error {
+	root, err := FindModuleRoot(dir)
+	if err != nil {
+		return err
+	}
+	g, err := Load(root, opts.Tests)
+	if err != nil {
+		return err
+	}
+	if len(g.Packages) == 0 {
+		return fmt.Errorf("No Go packages found.")
+	}
+
+	if opts.Importers != "" {
+		pkg, err := g.Resolve(opts.Importers)
+		if err != nil {
+			return err
+		}
+		for _, p := range g.ImportedBy(pkg, opts.Transitive) {
+			fmt.Fprintln(w, p)
+		}
+		return nil
+	}
+
+	cycles := g.Cycles()
+	if opts.DOT {
+		writeDOT(w, g, cycles)
+		return nil
+	}
+
+	fmt.Fprintf(w, "module %s (%d packages)\n", g.Module, len(g.Packages))
+	for _, path := range g.Sorted() {
+		p := g.Packages[path]
+		fmt.Fprintf(w, "\n%s\n", path)
+		for _, imp := range p.Imports {
+			fmt.Fprintf(w, "  -> %s\n", imp)
+		}
+		if opts.External {
+			for _, imp := range p.External {
+				fmt.Fprintf(w, "  -> %s (external)\n", imp)
+			}
+		}
+	}
+
+	fmt.Fprintln(w)
+	if len(cycles) == 0 {
+		fmt.Fprintln(w, "No import cycles.")
+		return nil
+	}
+	fmt.Fprintf(w, "Import cycles (%d):\n", len(cycles))
+	for _, c := range cycles {
+		fmt.Fprintf(w, "  %s\n", strings.Join(c, " -> "))
+	}
+	return nil
+}
+
+// writeDOT writes the internal graph in Graphviz format, with cycle edges
+// drawn in red.
+func writeDOT(w io.Writer, g *Graph, cycles [][]string) {
+	cycleEdge := make(map[[2]string]bool)
+	for _, c := range cycles {
+		for i := 0; i+1 < len(c); i++ {
+			cycleEdge[[2]string{c[i], c[i+1]}] = true
+		}
+	}
+
+	fmt.Fprintf(w, "digraph %q {\n", g.Module)
+	fmt.Fprintln(w, "  rankdir=LR;")
+	fmt.Fprintln(w, "  node [shape=box];")
+	for _, path := range g.Sorted() {
+		p := g.Packages[path]
+		if len(p.Imports) == 0 {
+			fmt.Fprintf(w, "  %q;\n", path)
+		}
+		for _, imp := range p.Imports {
+			attrs := ""
+			if cycleEdge[[2]string{path, imp}] {
+				attrs = " [color=red]"
+			}
+			fmt.Fprintf(w, "  %q -> %q%s;\n", path, imp, attrs)
+		}
+	}
+	fmt.Fprintln(w, "}")
+}
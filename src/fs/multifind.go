@@ -5,8 +5,14 @@ import (
 	"fmt"
 	"io"
 	"os/exec"
+	"path/filepath"
+	"strings"
 )
 
+// ExcludeNames are file and directory name globs whose matches, and
+// anything below them, are dropped from MultiFind results.
+var ExcludeNames []string
+
 func MultiFind(w io.Writer, headCount int, findOpts []string, paths []string) {
 	for _, p := range paths {
 		fmt.Fprintf(w, "==> %s <==\n", p)
@@ -32,6 +38,9 @@ func MultiFind(w io.Writer, headCount int, findOpts []string, paths []string) {
 			if count >= headCount {
 				break
 			}
+			if excluded(p, scanner.Text()) {
+				continue
+			}
 			fmt.Fprintln(w, scanner.Text())
 			count++
 		}
@@ -43,3 +52,20 @@ func MultiFind(w io.Writer, headCount int, findOpts []string, paths []string) {
 		fmt.Fprintln(w, "---")
 	}
 }
+
+// excluded reports whether a path found under root matches ExcludeNames in
+// one of its components below root; root itself is never excluded.
+func excluded(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return false
+	}
+	for _, part := range strings.Split(filepath.ToSlash(rel), "/") {
+		for _, pattern := range ExcludeNames {
+			if ok, _ := filepath.Match(pattern, part); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -48,3 +48,45 @@ func TestMultiFind_HeadLimit(t *testing.T) {
 		t.Errorf("got %d content lines, want <= 3", contentLines)
 	}
 }
+
+func TestMultiFind_ExcludeNames(t *testing.T) {
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, "gen"), 0755)
+	for _, name := range []string{"a.txt", "b.min.txt", "gen/c.txt"} {
+		os.WriteFile(filepath.Join(dir, name), []byte(""), 0644)
+	}
+	ExcludeNames = []string{"gen", "*.min.txt"}
+	defer func() { ExcludeNames = nil }()
+
+	var buf bytes.Buffer
+	MultiFind(&buf, 10, []string{"-name", "*.txt"}, []string{dir})
+	out := buf.String()
+
+	if !strings.Contains(out, "a.txt") {
+		t.Errorf("missing a.txt in:\n%s", out)
+	}
+	if strings.Contains(out, "b.min.txt") || strings.Contains(out, "c.txt") {
+		t.Errorf("excluded files listed:\n%s", out)
+	}
+}
+
+func TestMultiFind_ExcludeNamesRoot(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "build")
+	os.MkdirAll(filepath.Join(dir, "build"), 0755)
+	for _, name := range []string{"a.txt", "build/b.txt"} {
+		os.WriteFile(filepath.Join(dir, name), []byte(""), 0644)
+	}
+	ExcludeNames = []string{"build"}
+	defer func() { ExcludeNames = nil }()
+
+	var buf bytes.Buffer
+	MultiFind(&buf, 10, []string{"-name", "*.txt"}, []string{dir + "/"})
+	out := buf.String()
+
+	if !strings.Contains(out, "a.txt") {
+		t.Errorf("search root under an excluded name hid its files:\n%s", out)
+	}
+	if strings.Contains(out, "b.txt") {
+		t.Errorf("excluded directory below the root listed:\n%s", out)
+	}
+}
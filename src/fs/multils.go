@@ -4,19 +4,39 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
+
+	"repotools/src/output"
 )
 
 func MultiLS(w io.Writer, dirs []string) {
-	for _, d := range dirs {
-		fmt.Fprintf(w, "==> %s <==\n", d)
+	MultiLSBudget(w, dirs, output.Budget{})
+}
+
+// MultiLSBudget is MultiLS with the budget shared fairly between the
+// directories, so one large directory does not hide the others.
+func MultiLSBudget(w io.Writer, dirs []string, b output.Budget) {
+	listings := make([]string, len(dirs))
+	var frame strings.Builder
+	for i, d := range dirs {
+		fmt.Fprintf(&frame, "==> %s <==\n---\n", d)
+		var sb strings.Builder
 		entries, err := os.ReadDir(d)
 		if err != nil {
-			fmt.Fprintln(w, err)
+			fmt.Fprintln(&sb, err)
 		} else {
 			for _, e := range entries {
-				fmt.Fprintln(w, e.Name())
+				fmt.Fprintln(&sb, e.Name())
 			}
 		}
+		listings[i] = sb.String()
+	}
+	if b.Limited() {
+		listings = output.TruncateSections(listings, b, frame.String())
+	}
+	for i, d := range dirs {
+		fmt.Fprintf(w, "==> %s <==\n", d)
+		io.WriteString(w, listings[i])
 		fmt.Fprintln(w, "---")
 	}
 }
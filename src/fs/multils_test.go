@@ -2,10 +2,13 @@ package fs
 
 import (
 	"bytes"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"repotools/src/output"
 )
 
 func TestMultiLS(t *testing.T) {
@@ -31,3 +34,32 @@ func TestMultiLS(t *testing.T) {
 		t.Errorf("missing b.txt in output")
 	}
 }
+
+func TestMultiLSBudget(t *testing.T) {
+	dir1 := t.TempDir()
+	dir2 := t.TempDir()
+	os.WriteFile(filepath.Join(dir1, "a.txt"), []byte(""), 0644)
+	for i := 0; i < 20; i++ {
+		os.WriteFile(filepath.Join(dir2, fmt.Sprintf("b%02d.txt", i)), []byte(""), 0644)
+	}
+
+	var buf bytes.Buffer
+	MultiLSBudget(&buf, []string{dir1, dir2}, output.Budget{MaxLines: 10})
+	out := buf.String()
+
+	if n := strings.Count(out, "\n"); n != 10 {
+		t.Errorf("got %d lines, want 10:\n%s", n, out)
+	}
+	if !strings.Contains(out, "a.txt") {
+		t.Errorf("small directory lost its entry:\n%s", out)
+	}
+	if !strings.Contains(out, "b00.txt") || strings.Contains(out, "b19.txt") {
+		t.Errorf("large directory not truncated from the end:\n%s", out)
+	}
+	if !strings.Contains(out, "[16 more lines omitted, rerun with --max-lines=25]") {
+		t.Errorf("missing omission marker:\n%s", out)
+	}
+	if strings.Count(out, "---") != 2 {
+		t.Errorf("separators should survive truncation:\n%s", out)
+	}
+}
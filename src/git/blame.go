@@ -0,0 +1,224 @@
+package git
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"repotools/src/runner"
+)
+
+// uncommitted is the hash git blame gives lines not committed yet.
+const uncommitted = "0000000000000000000000000000000000000000"
+
+// BlameLine is one line of a file with the commit that last changed it.
+type BlameLine struct {
+	Line    int    `json:"line"`
+	Hash    string `json:"hash"`
+	Author  string `json:"author"`
+	Date    string `json:"date"`
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+}
+
+// Blame runs git blame --porcelain on path, limited to lines start..end
+// when they are set.
+func Blame(path string, start, end int) ([]BlameLine, error) {
+	args := []string{"git", "blame", "--porcelain"}
+	if start > 0 || end > 0 {
+		args = append(args, fmt.Sprintf("-L%s,%s", blameBound(start), blameBound(end)))
+	}
+	r, err := runner.RunNoCheck(append(args, "--", path))
+	if err != nil {
+		return nil, err
+	}
+	if r.ExitCode != 0 {
+		return nil, fmt.Errorf("git blame %s: %s", path, strings.TrimSpace(r.Stderr))
+	}
+	return parseBlame(r.Stdout), nil
+}
+
+func blameBound(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// parseBlame reads porcelain output, where each commit's details follow
+// its first line only.
+func parseBlame(out string) []BlameLine {
+	type commitInfo struct{ author, date, subject string }
+	commits := make(map[string]*commitInfo)
+	var lines []BlameLine
+	var cur *BlameLine
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "\t") {
+			if cur != nil {
+				c := commits[cur.Hash]
+				cur.Author, cur.Date, cur.Subject = c.author, c.date, c.subject
+				cur.Text = line[1:]
+				lines = append(lines, *cur)
+				cur = nil
+			}
+			continue
+		}
+		key, val, _ := strings.Cut(line, " ")
+		if cur == nil {
+			f := strings.Fields(line)
+			if len(f) < 3 || len(f[0]) != 40 {
+				continue
+			}
+			n, _ := strconv.Atoi(f[2])
+			cur = &BlameLine{Line: n, Hash: f[0]}
+			if commits[f[0]] == nil {
+				commits[f[0]] = &commitInfo{}
+			}
+			continue
+		}
+		c := commits[cur.Hash]
+		switch key {
+		case "author":
+			c.author = val
+		case "author-time":
+			if t, err := strconv.ParseInt(val, 10, 64); err == nil {
+				c.date = time.Unix(t, 0).UTC().Format("2006-01-02")
+			}
+		case "summary":
+			c.subject = val
+		}
+	}
+	for i := range lines {
+		if lines[i].Hash == uncommitted {
+			lines[i].Hash, lines[i].Date, lines[i].Subject = "", "", ""
+		} else {
+			lines[i].Hash = lines[i].Hash[:7]
+		}
+	}
+	return lines
+}
+
+// BlameGroup is a run of consecutive lines last changed by one commit.
+type BlameGroup struct {
+	Start   int      `json:"start"`
+	End     int      `json:"end"`
+	Hash    string   `json:"hash"`
+	Author  string   `json:"author"`
+	Date    string   `json:"date"`
+	Subject string   `json:"subject"`
+	Lines   []string `json:"lines,omitempty"`
+}
+
+// GroupBlame merges consecutive lines from the same commit.
+func GroupBlame(lines []BlameLine) []BlameGroup {
+	var groups []BlameGroup
+	for _, l := range lines {
+		if n := len(groups); n > 0 && groups[n-1].Hash == l.Hash && groups[n-1].Author == l.Author && groups[n-1].End == l.Line-1 {
+			groups[n-1].End = l.Line
+			groups[n-1].Lines = append(groups[n-1].Lines, l.Text)
+			continue
+		}
+		groups = append(groups, BlameGroup{Start: l.Line, End: l.Line, Hash: l.Hash, Author: l.Author,
+			Date: l.Date, Subject: l.Subject, Lines: []string{l.Text}})
+	}
+	return groups
+}
+
+// AuthorShare is how many of the blamed lines an author last changed.
+type AuthorShare struct {
+	Author  string  `json:"author"`
+	Lines   int     `json:"lines"`
+	Percent float64 `json:"percent"`
+	Commits int     `json:"commits"`
+	// Last is the date of the author's newest commit among the lines.
+	Last string `json:"last"`
+}
+
+// SummarizeBlame counts lines per author, most lines first.
+func SummarizeBlame(lines []BlameLine) []AuthorShare {
+	byAuthor := make(map[string]*AuthorShare)
+	commits := make(map[string]map[string]bool)
+	for _, l := range lines {
+		s := byAuthor[l.Author]
+		if s == nil {
+			s = &AuthorShare{Author: l.Author}
+			byAuthor[l.Author] = s
+			commits[l.Author] = make(map[string]bool)
+		}
+		s.Lines++
+		commits[l.Author][l.Hash] = true
+		if l.Date > s.Last {
+			s.Last = l.Date
+		}
+	}
+	shares := make([]AuthorShare, 0, len(byAuthor))
+	for author, s := range byAuthor {
+		s.Commits = len(commits[author])
+		s.Percent = 100 * float64(s.Lines) / float64(len(lines))
+		shares = append(shares, *s)
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].Lines != shares[j].Lines {
+			return shares[i].Lines > shares[j].Lines
+		}
+		return shares[i].Author < shares[j].Author
+	})
+	return shares
+}
+
+// WriteBlame prints one line per group: line range, commit, date, author
+// and subject, followed by the group's numbered lines when withCode is set.
+func WriteBlame(w io.Writer, groups []BlameGroup, withCode, asJSON bool) error {
+	if asJSON {
+		if !withCode {
+			for i := range groups {
+				groups[i].Lines = nil
+			}
+		}
+		return writeJSON(w, nonNil(groups))
+	}
+	rangeWidth, authorWidth := 0, 0
+	for _, g := range groups {
+		rangeWidth = max(rangeWidth, len(formatLineRange(g.Start, g.End)))
+		authorWidth = max(authorWidth, len(g.Author))
+	}
+	for _, g := range groups {
+		hash, date, subject := g.Hash, g.Date, g.Subject
+		if hash == "" {
+			hash, date, subject = "-------", "----------", "(uncommitted)"
+		}
+		fmt.Fprintf(w, "%-*s  %s %s  %-*s  %s\n", rangeWidth, formatLineRange(g.Start, g.End), hash, date, authorWidth, g.Author, subject)
+		if withCode {
+			for i, text := range g.Lines {
+				fmt.Fprintf(w, "%6d\t%s\n", g.Start+i, text)
+			}
+		}
+	}
+	return nil
+}
+
+func formatLineRange(start, end int) string {
+	if start == end {
+		return strconv.Itoa(start)
+	}
+	return fmt.Sprintf("%d-%d", start, end)
+}
+
+// WriteBlameSummary prints each author's share of the lines.
+func WriteBlameSummary(w io.Writer, shares []AuthorShare, asJSON bool) error {
+	if asJSON {
+		return writeJSON(w, nonNil(shares))
+	}
+	authorWidth := 0
+	for _, s := range shares {
+		authorWidth = max(authorWidth, len(s.Author))
+	}
+	for _, s := range shares {
+		fmt.Fprintf(w, "%-*s  %5d lines  %5.1f%%  %3d %s  last %s\n", authorWidth, s.Author, s.Lines, s.Percent,
+			s.Commits, plural(s.Commits, "commit", "commits"), s.Last)
+	}
+	return nil
+}
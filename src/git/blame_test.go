@@ -0,0 +1,103 @@
+package git
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseBlame(t *testing.T) {
+	a := strings.Repeat("a", 40)
+	b := strings.Repeat("b", 40)
+	out := a + ` 1 1 2
+author Alice
+author-mail <alice@example.com>
+author-time 1767312000
+author-tz +0000
+summary first commit
+filename f.txt
+	one
+` + a + ` 2 2
+	two
+` + b + ` 1 3 1
+author Bob
+author-time 1767398400
+summary second commit
+filename f.txt
+	three
+` + uncommitted + ` 4 4 1
+author Not Committed Yet
+author-time 1767484800
+summary Version of f.txt from f.txt
+filename f.txt
+	four
+`
+	lines := parseBlame(out)
+	if len(lines) != 4 {
+		t.Fatalf("got %d lines, want 4: %+v", len(lines), lines)
+	}
+	want := []BlameLine{
+		{Line: 1, Hash: "aaaaaaa", Author: "Alice", Date: "2026-01-02", Subject: "first commit", Text: "one"},
+		{Line: 2, Hash: "aaaaaaa", Author: "Alice", Date: "2026-01-02", Subject: "first commit", Text: "two"},
+		{Line: 3, Hash: "bbbbbbb", Author: "Bob", Date: "2026-01-03", Subject: "second commit", Text: "three"},
+		{Line: 4, Author: "Not Committed Yet", Text: "four"},
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d = %+v, want %+v", i, lines[i], want[i])
+		}
+	}
+
+	groups := GroupBlame(lines)
+	if len(groups) != 3 || groups[0].Start != 1 || groups[0].End != 2 || len(groups[0].Lines) != 2 {
+		t.Errorf("GroupBlame = %+v", groups)
+	}
+
+	shares := SummarizeBlame(lines)
+	if len(shares) != 3 || shares[0].Author != "Alice" || shares[0].Lines != 2 || shares[0].Percent != 50 || shares[0].Commits != 1 {
+		t.Errorf("SummarizeBlame = %+v", shares)
+	}
+
+	var buf bytes.Buffer
+	WriteBlame(&buf, groups, false, false)
+	got := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(got) != 3 || !strings.HasPrefix(got[0], "1-2  aaaaaaa 2026-01-02  Alice") || !strings.Contains(got[2], "(uncommitted)") {
+		t.Errorf("WriteBlame =\n%s", buf.String())
+	}
+}
+
+func TestBlame_Range(t *testing.T) {
+	dir := setupGitRepo(t)
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	os.WriteFile(filepath.Join(dir, "file.txt"), []byte("hello\nworld\nagain\n"), 0644)
+	for _, args := range [][]string{{"git", "add", "."}, {"git", "commit", "-qm", "more"}} {
+		if out, err := exec.Command(args[0], args[1:]...).CombinedOutput(); err != nil {
+			t.Fatalf("%v: %s: %v", args, out, err)
+		}
+	}
+
+	lines, err := Blame("file.txt", 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 2 || lines[0].Line != 2 || lines[0].Subject != "more" || lines[1].Text != "again" {
+		t.Errorf("Blame(2,) = %+v", lines)
+	}
+
+	lines, err = Blame("file.txt", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if groups := GroupBlame(lines); len(groups) != 2 || groups[0].Subject != "initial" || groups[1].Start != 2 {
+		t.Errorf("GroupBlame = %+v", groups)
+	}
+	if _, err := Blame("missing.txt", 0, 0); err == nil {
+		t.Error("Blame(missing.txt) succeeded")
+	}
+}
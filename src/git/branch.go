@@ -0,0 +1,213 @@
+package git
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"repotools/src/runner"
+)
+
+// BranchInfo is a local branch with its upstream, merge and PR state.
+type BranchInfo struct {
+	Name    string `json:"name"`
+	Current bool   `json:"current,omitempty"`
+	// Upstream is the tracked remote branch; Gone is set when it has been
+	// deleted on the remote.
+	Upstream string `json:"upstream,omitempty"`
+	Gone     bool   `json:"gone,omitempty"`
+	// Ahead and Behind count commits relative to the upstream.
+	Ahead  int `json:"ahead"`
+	Behind int `json:"behind"`
+	// Merged is set when the branch is fully merged into the base branch.
+	Merged bool `json:"merged"`
+	// Stale is set when the last commit is older than the stale window.
+	Stale bool `json:"stale"`
+	// Worktree is the path of another worktree that has the branch checked
+	// out.
+	Worktree string `json:"worktree,omitempty"`
+	Last     Commit `json:"last"`
+	// PRNumber and PRState describe the newest PR with this head branch.
+	PRNumber int    `json:"pr,omitempty"`
+	PRState  string `json:"pr_state,omitempty"`
+}
+
+// ListBranches describes every local branch: upstream and ahead/behind it,
+// whether it is merged into base, and whether its last commit is before
+// staleBefore (zero to never mark branches stale).
+func ListBranches(base string, staleBefore time.Time) ([]BranchInfo, error) {
+	r, err := runner.Run([]string{"git", "for-each-ref", "refs/heads",
+		"--format=%(refname:short)%1f%(HEAD)%1f%(upstream:short)%1f%(upstream:track,nobracket)%1f" +
+			"%(objectname:short)%1f%(committerdate:short)%1f%(committerdate:unix)%1f%(authorname)%1f%(subject)"})
+	if err != nil {
+		return nil, err
+	}
+	branches := parseBranches(r.Stdout, staleBefore)
+
+	merged := make(map[string]bool)
+	// A base that does not exist leaves every branch unmerged.
+	if m, err := runner.RunNoCheck([]string{"git", "branch", "--merged", base, "--format=%(refname:short)"}); err == nil && m.ExitCode == 0 {
+		for _, name := range strings.Split(m.Stdout, "\n") {
+			merged[strings.TrimSpace(name)] = true
+		}
+	}
+	worktrees := make(map[string]string)
+	if wts, err := Worktrees(); err == nil {
+		for _, wt := range wts {
+			if wt.Branch != "" {
+				worktrees[wt.Branch] = wt.Path
+			}
+		}
+	}
+	for i := range branches {
+		b := &branches[i]
+		b.Merged = b.Name != base && merged[b.Name]
+		if !b.Current {
+			b.Worktree = worktrees[b.Name]
+		}
+	}
+	return branches, nil
+}
+
+func parseBranches(out string, staleBefore time.Time) []BranchInfo {
+	var branches []BranchInfo
+	for _, line := range strings.Split(out, "\n") {
+		f := strings.Split(line, "\x1f")
+		if len(f) != 9 {
+			continue
+		}
+		b := BranchInfo{
+			Name:     f[0],
+			Current:  f[1] == "*",
+			Upstream: f[2],
+			Last:     Commit{Hash: f[4], Date: f[5], Author: f[7], Subject: f[8]},
+		}
+		for _, part := range strings.Split(f[3], ", ") {
+			kind, n, _ := strings.Cut(part, " ")
+			switch kind {
+			case "gone":
+				b.Gone = true
+			case "ahead":
+				b.Ahead, _ = strconv.Atoi(n)
+			case "behind":
+				b.Behind, _ = strconv.Atoi(n)
+			}
+		}
+		if unix, err := strconv.ParseInt(f[6], 10, 64); err == nil && !staleBefore.IsZero() {
+			b.Stale = time.Unix(unix, 0).Before(staleBefore)
+		}
+		branches = append(branches, b)
+	}
+	return branches
+}
+
+// WindowStart turns a window like "90d", "12w", "6m" or "1y", or a
+// YYYY-MM-DD date, into the time it starts at counting back from now.
+func WindowStart(window string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", window); err == nil {
+		return t, nil
+	}
+	if len(window) > 1 {
+		if n, err := strconv.Atoi(window[:len(window)-1]); err == nil && n >= 0 {
+			switch window[len(window)-1] {
+			case 'd':
+				return now.AddDate(0, 0, -n), nil
+			case 'w':
+				return now.AddDate(0, 0, -7*n), nil
+			case 'm':
+				return now.AddDate(0, -n, 0), nil
+			case 'y':
+				return now.AddDate(-n, 0, 0), nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid window %q (want Nd, Nw, Nm, Ny or YYYY-MM-DD)", window)
+}
+
+// Prunable returns the merged branches that can be deleted: not checked out
+// here or in another worktree.
+func Prunable(branches []BranchInfo) []BranchInfo {
+	var out []BranchInfo
+	for _, b := range branches {
+		if b.Merged && !b.Current && b.Worktree == "" {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// DeleteBranch deletes a local branch with `git branch -d`, which refuses
+// branches that are not merged.
+func DeleteBranch(name string) error {
+	r, err := runner.RunNoCheck([]string{"git", "branch", "-d", name})
+	if err != nil {
+		return err
+	}
+	if r.ExitCode != 0 {
+		return fmt.Errorf("deleting %s: %s", name, strings.TrimSpace(r.Stderr))
+	}
+	return nil
+}
+
+// WriteBranches prints one line per branch: a * for the current one, name,
+// upstream with ahead/behind, PR, cleanup flags and the last commit.
+func WriteBranches(w io.Writer, branches []BranchInfo, asJSON bool) error {
+	if asJSON {
+		return writeJSON(w, nonNil(branches))
+	}
+	rows := make([][3]string, len(branches))
+	var widths [3]int
+	for i, b := range branches {
+		rows[i] = [3]string{b.Name, branchUpstream(b), branchPR(b)}
+		for j, col := range rows[i] {
+			widths[j] = max(widths[j], len(col))
+		}
+	}
+	for i, b := range branches {
+		mark := " "
+		if b.Current {
+			mark = "*"
+		}
+		fmt.Fprintf(w, "%s %-*s  %-*s  %-*s  %-12s  %s %s %s\n", mark,
+			widths[0], rows[i][0], widths[1], rows[i][1], widths[2], rows[i][2],
+			branchFlags(b), b.Last.Hash, b.Last.Date, b.Last.Subject)
+	}
+	return nil
+}
+
+func branchUpstream(b BranchInfo) string {
+	switch {
+	case b.Upstream == "":
+		return "-"
+	case b.Gone:
+		return b.Upstream + " gone"
+	}
+	return fmt.Sprintf("%s +%d -%d", b.Upstream, b.Ahead, b.Behind)
+}
+
+func branchPR(b BranchInfo) string {
+	if b.PRNumber == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("#%d %s", b.PRNumber, b.PRState)
+}
+
+// branchFlags names why a branch is a cleanup candidate, or "-".
+func branchFlags(b BranchInfo) string {
+	var flags []string
+	if b.Merged {
+		flags = append(flags, "merged")
+	}
+	if b.Gone {
+		flags = append(flags, "gone")
+	}
+	if b.Stale {
+		flags = append(flags, "stale")
+	}
+	if len(flags) == 0 {
+		return "-"
+	}
+	return strings.Join(flags, ",")
+}
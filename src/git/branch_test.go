@@ -0,0 +1,115 @@
+package git
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseBranches(t *testing.T) {
+	line := func(f ...string) string { return strings.Join(f, "\x1f") }
+	out := strings.Join([]string{
+		line("main", "*", "origin/main", "ahead 1, behind 2", "abc1234", "2026-01-02", "1767312000", "A", "latest"),
+		line("old", " ", "origin/old", "gone", "def5678", "2025-01-01", "1735689600", "B", "ancient"),
+		line("local", " ", "", "", "0123456", "2026-01-01", "1767225600", "C", "wip"),
+	}, "\n") + "\n"
+
+	branches := parseBranches(out, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+	if len(branches) != 3 {
+		t.Fatalf("got %d branches, want 3", len(branches))
+	}
+	main, old, local := branches[0], branches[1], branches[2]
+	if !main.Current || main.Upstream != "origin/main" || main.Ahead != 1 || main.Behind != 2 || main.Stale {
+		t.Errorf("main = %+v", main)
+	}
+	if !old.Gone || !old.Stale || old.Last.Subject != "ancient" {
+		t.Errorf("old = %+v", old)
+	}
+	if local.Upstream != "" || local.Gone || local.Stale || local.Last.Hash != "0123456" {
+		t.Errorf("local = %+v", local)
+	}
+}
+
+func TestWindowStart(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	for window, want := range map[string]string{
+		"10d":        "2026-03-21",
+		"2w":         "2026-03-17",
+		"1m":         "2026-03-03",
+		"1y":         "2025-03-31",
+		"2026-01-15": "2026-01-15",
+	} {
+		got, err := WindowStart(window, now)
+		if err != nil || got.Format("2006-01-02") != want {
+			t.Errorf("WindowStart(%q) = %v, %v, want %s", window, got, err, want)
+		}
+	}
+	if _, err := WindowStart("soon", now); err == nil {
+		t.Error("WindowStart(soon) succeeded")
+	}
+}
+
+func TestListBranchesAndPrune(t *testing.T) {
+	dir := setupGitRepo(t)
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	run := func(args ...string) {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("%v: %s: %v", args, out, err)
+		}
+	}
+	run("git", "branch", "done")
+	run("git", "worktree", "add", "-q", filepath.Join(t.TempDir(), "wt"), "-b", "checked-out")
+	run("git", "checkout", "-q", "-b", "feature")
+	os.WriteFile(filepath.Join(dir, "new.txt"), []byte("x\n"), 0644)
+	run("git", "add", ".")
+	run("git", "commit", "-qm", "feature work")
+	run("git", "checkout", "-q", "main")
+
+	branches, err := ListBranches("main", time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]BranchInfo)
+	for _, b := range branches {
+		byName[b.Name] = b
+	}
+	if b := byName["main"]; !b.Current || b.Merged {
+		t.Errorf("main = %+v", b)
+	}
+	if b := byName["done"]; !b.Merged || b.Worktree != "" {
+		t.Errorf("done = %+v", b)
+	}
+	if b := byName["checked-out"]; !b.Merged || b.Worktree == "" {
+		t.Errorf("checked-out = %+v", b)
+	}
+	if b := byName["feature"]; b.Merged || b.Last.Subject != "feature work" {
+		t.Errorf("feature = %+v", b)
+	}
+
+	prunable := Prunable(branches)
+	if len(prunable) != 1 || prunable[0].Name != "done" {
+		t.Fatalf("Prunable = %+v, want only done", prunable)
+	}
+	if err := DeleteBranch("done"); err != nil {
+		t.Fatal(err)
+	}
+	if err := DeleteBranch("feature"); err == nil {
+		t.Error("DeleteBranch deleted an unmerged branch")
+	}
+
+	var buf bytes.Buffer
+	WriteBranches(&buf, []BranchInfo{byName["main"], byName["done"]}, false)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "* main") || !strings.Contains(lines[1], "merged") {
+		t.Errorf("WriteBranches =\n%s", buf.String())
+	}
+}
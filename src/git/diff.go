@@ -0,0 +1,431 @@
+package git
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"repotools/src/runner"
+)
+
+// Hunk is one @@ section of a unified diff.
+type Hunk struct {
+	OldStart int `json:"old_start"`
+	OldLines int `json:"old_lines"`
+	NewStart int `json:"new_start"`
+	NewLines int `json:"new_lines"`
+	// Header is the text after the closing @@ (git's function context).
+	Header string `json:"header,omitempty"`
+	// Function is the function the hunk's first change falls in, when a
+	// caller has located it; it replaces Header when printing.
+	Function string `json:"function,omitempty"`
+	Lines    []Line `json:"lines,omitempty"`
+}
+
+// Line is one line of a hunk body. Op is " " for context, "+" or "-" for a
+// change, and a backslash for the "\ No newline at end of file" marker.
+// Old and New are the line numbers on each side, zero where absent.
+type Line struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+	Old  int    `json:"old,omitempty"`
+	New  int    `json:"new,omitempty"`
+}
+
+// NewRange returns the first and last new-side line the hunk touches. A pure
+// deletion touches the line just before the removed lines.
+func (h Hunk) NewRange() (start, end int) {
+	if h.NewLines == 0 {
+		return h.NewStart, h.NewStart
+	}
+	return h.NewStart, h.NewStart + h.NewLines - 1
+}
+
+// FirstChange returns the new-side line of the hunk's first added line, or
+// for a deletion the line just before the removed ones. Without parsed
+// lines it is the hunk's new start.
+func (h Hunk) FirstChange() int {
+	prev := h.NewStart - 1
+	for _, l := range h.Lines {
+		switch l.Op {
+		case "+":
+			return l.New
+		case "-":
+			return max(prev, 1)
+		case " ":
+			prev = l.New
+		}
+	}
+	return h.NewStart
+}
+
+// OldRange is NewRange for the old side of the hunk.
+func (h Hunk) OldRange() (start, end int) {
+	if h.OldLines == 0 {
+		return h.OldStart, h.OldStart
+	}
+	return h.OldStart, h.OldStart + h.OldLines - 1
+}
+
+// FileDiff is the diff of one file.
+type FileDiff struct {
+	OldPath string `json:"old_path,omitempty"`
+	NewPath string `json:"new_path,omitempty"`
+	// Header holds the raw lines from "diff --git" up to the first hunk.
+	Header []string `json:"-"`
+	// Binary is set for "Binary files ... differ" entries.
+	Binary bool   `json:"binary,omitempty"`
+	Hunks  []Hunk `json:"hunks"`
+}
+
+// Path is the file's new path, or its old path when deleted.
+func (f FileDiff) Path() string {
+	if f.NewPath == "" {
+		return f.OldPath
+	}
+	return f.NewPath
+}
+
+// Added counts the added lines of the file.
+func (f FileDiff) Added() int { return f.count("+") }
+
+// Removed counts the removed lines of the file.
+func (f FileDiff) Removed() int { return f.count("-") }
+
+func (f FileDiff) count(op string) int {
+	n := 0
+	for _, h := range f.Hunks {
+		for _, l := range h.Lines {
+			if l.Op == op {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// ParseDiff reads a unified git diff into files, hunks and lines. Hunk
+// bodies are consumed by their line counts, so removed lines that look
+// like headers ("--- x") stay in their hunk.
+func ParseDiff(patch string) []FileDiff {
+	var files []FileDiff
+	var cur *FileDiff
+	var hunk *Hunk
+	oldLeft, newLeft := 0, 0
+	oldNo, newNo := 0, 0
+	for _, line := range strings.Split(strings.TrimSuffix(patch, "\n"), "\n") {
+		if hunk != nil && (oldLeft > 0 || newLeft > 0) {
+			l := Line{Text: line}
+			if line != "" {
+				l.Op, l.Text = line[:1], line[1:]
+			} else {
+				// Some tools strip the space of empty context lines.
+				l.Op = " "
+			}
+			switch l.Op {
+			case " ":
+				l.Old, l.New = oldNo, newNo
+				oldNo, newNo, oldLeft, newLeft = oldNo+1, newNo+1, oldLeft-1, newLeft-1
+			case "-":
+				l.Old = oldNo
+				oldNo, oldLeft = oldNo+1, oldLeft-1
+			case "+":
+				l.New = newNo
+				newNo, newLeft = newNo+1, newLeft-1
+			case "\\":
+				l.Text = strings.TrimPrefix(l.Text, " ")
+			}
+			hunk.Lines = append(hunk.Lines, l)
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			files = append(files, FileDiff{Header: []string{line}})
+			cur, hunk = &files[len(files)-1], nil
+			if a, b, ok := splitDiffGitLine(line); ok {
+				cur.OldPath, cur.NewPath = a, b
+			}
+		case cur == nil:
+		case hunk != nil && strings.HasPrefix(line, "\\"):
+			hunk.Lines = append(hunk.Lines, Line{Op: "\\", Text: strings.TrimPrefix(line[1:], " ")})
+		case strings.HasPrefix(line, "@@ "):
+			if h, ok := parseHunkHeader(line); ok {
+				cur.Hunks = append(cur.Hunks, h)
+				hunk = &cur.Hunks[len(cur.Hunks)-1]
+				oldLeft, newLeft = h.OldLines, h.NewLines
+				oldNo, newNo = h.OldStart, h.NewStart
+			}
+		case hunk != nil:
+			// Stray text after a hunk is not part of the diff.
+		default:
+			cur.Header = append(cur.Header, line)
+			switch {
+			case strings.HasPrefix(line, "--- "):
+				cur.OldPath = diffPath(line[4:], "a/")
+			case strings.HasPrefix(line, "+++ "):
+				cur.NewPath = diffPath(line[4:], "b/")
+			case strings.HasPrefix(line, "Binary files "):
+				cur.Binary = true
+			}
+		}
+	}
+	return files
+}
+
+func splitDiffGitLine(line string) (string, string, bool) {
+	rest := strings.TrimPrefix(line, "diff --git ")
+	i := strings.Index(rest, " b/")
+	if i < 0 || !strings.HasPrefix(rest, "a/") {
+		return "", "", false
+	}
+	return rest[2:i], rest[i+3:], true
+}
+
+func diffPath(p, prefix string) string {
+	p = strings.TrimSpace(p)
+	if p == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(p, prefix)
+}
+
+// parseHunkHeader parses "@@ -a,b +c,d @@ context".
+func parseHunkHeader(line string) (Hunk, bool) {
+	end := strings.Index(line[3:], " @@")
+	if end < 0 {
+		return Hunk{}, false
+	}
+	fields := strings.Fields(line[3 : 3+end])
+	if len(fields) != 2 {
+		return Hunk{}, false
+	}
+	var h Hunk
+	var ok1, ok2 bool
+	h.OldStart, h.OldLines, ok1 = parseRange(strings.TrimPrefix(fields[0], "-"))
+	h.NewStart, h.NewLines, ok2 = parseRange(strings.TrimPrefix(fields[1], "+"))
+	h.Header = strings.TrimSpace(line[3+end+3:])
+	return h, ok1 && ok2
+}
+
+func parseRange(s string) (start, count int, ok bool) {
+	startStr, countStr, hasCount := strings.Cut(s, ",")
+	start, err := strconv.Atoi(startStr)
+	if err != nil {
+		return 0, 0, false
+	}
+	count = 1
+	if hasCount {
+		if count, err = strconv.Atoi(countStr); err != nil {
+			return 0, 0, false
+		}
+	}
+	return start, count, true
+}
+
+// DiffPreamble returns the text ParseDiff skips before the first file diff,
+// such as the summary --stat or --summary print ahead of the patches.
+func DiffPreamble(patch string) string {
+	if strings.HasPrefix(patch, "diff --git ") {
+		return ""
+	}
+	if i := strings.Index(patch, "\ndiff --git "); i >= 0 {
+		return patch[:i+1]
+	}
+	return ""
+}
+
+// FilterDiff keeps the files whose path matches the include glob (all when
+// empty) and not the exclude glob. A glob without a slash matches the base
+// name; "**/" matches any number of directories.
+func FilterDiff(files []FileDiff, include, exclude string) []FileDiff {
+	var out []FileDiff
+	for _, f := range files {
+		if include != "" && !matchPathGlob(f.Path(), include) {
+			continue
+		}
+		if exclude != "" && matchPathGlob(f.Path(), exclude) {
+			continue
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+func matchPathGlob(p, pattern string) bool {
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(p))
+		return ok
+	}
+	if rest, ok := strings.CutPrefix(pattern, "**/"); ok {
+		parts := strings.Split(p, "/")
+		for i := range parts {
+			if matchPathGlob(strings.Join(parts[i:], "/"), rest) {
+				return true
+			}
+		}
+		return false
+	}
+	ok, _ := path.Match(pattern, p)
+	return ok
+}
+
+// SelectHunk narrows files to one hunk given as "file:N", N counting from 1.
+// The file is matched by path, or by a path suffix when unambiguous.
+func SelectHunk(files []FileDiff, spec string) ([]FileDiff, error) {
+	name, numStr, ok := cutLast(spec, ":")
+	n, err := strconv.Atoi(numStr)
+	if !ok || err != nil || n < 1 {
+		return nil, fmt.Errorf("invalid hunk %q (want file:N)", spec)
+	}
+	var matches []FileDiff
+	for _, f := range files {
+		if f.Path() == name {
+			matches = []FileDiff{f}
+			break
+		}
+		if strings.HasSuffix(f.Path(), "/"+name) {
+			matches = append(matches, f)
+		}
+	}
+	switch {
+	case len(matches) == 0:
+		return nil, fmt.Errorf("no changes to %s", name)
+	case len(matches) > 1:
+		return nil, fmt.Errorf("%s matches %d files; give more of the path", name, len(matches))
+	}
+	f := matches[0]
+	if n > len(f.Hunks) {
+		return nil, fmt.Errorf("%s has %d hunks", f.Path(), len(f.Hunks))
+	}
+	f.Hunks = []Hunk{f.Hunks[n-1]}
+	return []FileDiff{f}, nil
+}
+
+func cutLast(s, sep string) (string, string, bool) {
+	i := strings.LastIndex(s, sep)
+	if i < 0 {
+		return s, "", false
+	}
+	return s[:i], s[i+len(sep):], true
+}
+
+// DiffFrom diffs the working tree against rev with zero context over args
+// ("--" paths) and parses the hunks. Paths are relative to the working
+// directory.
+func DiffFrom(rev string, args ...string) ([]FileDiff, error) {
+	gitArgs := append([]string{"git", "diff", "-U0", "--no-color", "--no-renames", "--relative", rev}, args...)
+	r, err := runner.Run(gitArgs)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDiff(r.Stdout), nil
+}
+
+// Show returns the contents of path (relative to the working directory) at
+// rev.
+func Show(rev, path string) (string, error) {
+	r, err := runner.Run([]string{"git", "show", rev + ":./" + filepath.ToSlash(path)})
+	if err != nil {
+		return "", err
+	}
+	return r.Stdout, nil
+}
+
+// Diff runs git diff with args (revisions, options, "--" paths) and returns
+// the patch text, uncolored.
+func Diff(args ...string) (string, error) {
+	r, err := runner.Run(append([]string{"git", "diff", "--no-color"}, args...))
+	if err != nil {
+		return "", err
+	}
+	return r.Stdout, nil
+}
+
+// ShowFromRoot is Show for a path relative to the repository root, as git
+// diff prints paths without --relative.
+func ShowFromRoot(rev, path string) (string, error) {
+	r, err := runner.Run([]string{"git", "show", rev + ":" + filepath.ToSlash(path)})
+	if err != nil {
+		return "", err
+	}
+	return r.Stdout, nil
+}
+
+// CommitDiff is the parsed patch of one commit.
+type CommitDiff struct {
+	Hash  string
+	Files []FileDiff
+}
+
+// LogDiffs runs git log -p with zero context over args (ranges, --since,
+// "--" paths) and parses each commit's hunks. Paths are relative to the
+// working directory.
+func LogDiffs(args ...string) ([]CommitDiff, error) {
+	gitArgs := append([]string{"git", "log", "-p", "-U0", "--no-color", "--no-renames", "--relative", "--format=%x00%h"}, args...)
+	r, err := runner.Run(gitArgs)
+	if err != nil {
+		return nil, err
+	}
+	var commits []CommitDiff
+	for _, chunk := range strings.Split(r.Stdout, "\x00") {
+		if strings.TrimSpace(chunk) == "" {
+			continue
+		}
+		hash, patch, _ := strings.Cut(chunk, "\n")
+		commits = append(commits, CommitDiff{Hash: strings.TrimSpace(hash), Files: ParseDiff(patch)})
+	}
+	return commits, nil
+}
+
+// FileChurn is how often and how much a file changed.
+type FileChurn struct {
+	Path    string
+	Commits int
+	Added   int
+	Deleted int
+}
+
+// Churn sums git log --numstat over args (ranges, --since, "--" paths) per
+// file. Paths are relative to the working directory; binary files count
+// commits but no lines.
+func Churn(args ...string) (map[string]*FileChurn, error) {
+	gitArgs := append([]string{"git", "log", "--numstat", "--no-renames", "--relative", "--format="}, args...)
+	r, err := runner.Run(gitArgs)
+	if err != nil {
+		return nil, err
+	}
+	churn := make(map[string]*FileChurn)
+	for _, line := range strings.Split(r.Stdout, "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		fc := churn[fields[2]]
+		if fc == nil {
+			fc = &FileChurn{Path: fields[2]}
+			churn[fields[2]] = fc
+		}
+		fc.Commits++
+		added, _ := strconv.Atoi(fields[0])
+		deleted, _ := strconv.Atoi(fields[1])
+		fc.Added += added
+		fc.Deleted += deleted
+	}
+	return churn, nil
+}
+
+// SinceArg turns a window like "90d", "12w", "6m" or "1y" into a git
+// --since value; anything else (dates, git approxidates) passes through.
+func SinceArg(window string) string {
+	if len(window) > 1 {
+		if n, err := strconv.Atoi(window[:len(window)-1]); err == nil {
+			unit := map[byte]string{'d': "days", 'w': "weeks", 'm': "months", 'y': "years"}[window[len(window)-1]]
+			if unit != "" {
+				return fmt.Sprintf("--since=%d.%s.ago", n, unit)
+			}
+		}
+	}
+	return "--since=" + window
+}
@@ -0,0 +1,227 @@
+package git
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+func TestParseDiff(t *testing.T) {
+	patch := `diff --git a/a.go b/a.go
+index 1111111..2222222 100644
+--- a/a.go
++++ b/a.go
+@@ -3,0 +4,2 @@ func main() {
++	x := 1
++	y := 2
+@@ -10 +12 @@ func helper() {
+-	old
++	new
+diff --git a/gone.txt b/gone.txt
+deleted file mode 100644
+--- a/gone.txt
++++ /dev/null
+@@ -1,2 +0,0 @@
+-a
+-b
+`
+	files := ParseDiff(patch)
+	if len(files) != 2 {
+		t.Fatalf("got %d files, want 2", len(files))
+	}
+	a := files[0]
+	if a.Path() != "a.go" || len(a.Hunks) != 2 {
+		t.Fatalf("first file = %+v", a)
+	}
+	if h := a.Hunks[0]; h.OldStart != 3 || h.OldLines != 0 || h.NewStart != 4 || h.NewLines != 2 || h.Header != "func main() {" {
+		t.Errorf("hunk 0 = %+v", h)
+	}
+	if start, end := a.Hunks[1].NewRange(); start != 12 || end != 12 {
+		t.Errorf("hunk 1 range = %d-%d, want 12-12", start, end)
+	}
+	gone := files[1]
+	if gone.NewPath != "" || gone.Path() != "gone.txt" {
+		t.Errorf("deleted file = %+v", gone)
+	}
+	if start, end := gone.Hunks[0].NewRange(); start != 0 || end != 0 {
+		t.Errorf("deletion range = %d-%d, want 0-0", start, end)
+	}
+}
+
+func TestParseDiff_Lines(t *testing.T) {
+	patch := `diff --git a/a.txt b/a.txt
+index 1111111..2222222 100644
+--- a/a.txt
++++ b/a.txt
+@@ -1,4 +1,4 @@
+ one
+--- not a header
++++ not a header either
+ three
+-four
+\ No newline at end of file
++four
+`
+	files := ParseDiff(patch)
+	if len(files) != 1 || files[0].OldPath != "a.txt" || len(files[0].Hunks) != 1 {
+		t.Fatalf("files = %+v", files)
+	}
+	if len(files[0].Header) != 4 {
+		t.Errorf("header = %q", files[0].Header)
+	}
+	h := files[0].Hunks[0]
+	want := []Line{
+		{Op: " ", Text: "one", Old: 1, New: 1},
+		{Op: "-", Text: "-- not a header", Old: 2},
+		{Op: "+", Text: "++ not a header either", New: 2},
+		{Op: " ", Text: "three", Old: 3, New: 3},
+		{Op: "-", Text: "four", Old: 4},
+		{Op: "\\", Text: "No newline at end of file"},
+		{Op: "+", Text: "four", New: 4},
+	}
+	if !reflect.DeepEqual(h.Lines, want) {
+		t.Errorf("lines =\n%+v\nwant\n%+v", h.Lines, want)
+	}
+	if files[0].Added() != 2 || files[0].Removed() != 2 {
+		t.Errorf("added/removed = %d/%d, want 2/2", files[0].Added(), files[0].Removed())
+	}
+	if got := h.FirstChange(); got != 1 {
+		t.Errorf("FirstChange = %d, want 1 (line before the deletion)", got)
+	}
+
+	var buf bytes.Buffer
+	WritePatch(&buf, files)
+	if buf.String() != patch {
+		t.Errorf("WritePatch did not round-trip:\n%s", buf.String())
+	}
+}
+
+func TestDiffPreamble(t *testing.T) {
+	patch := "diff --git a/f b/f\n--- a/f\n+++ b/f\n@@ -1 +1 @@\n-a\n+b\n"
+	if got := DiffPreamble(patch); got != "" {
+		t.Errorf("plain patch preamble = %q", got)
+	}
+	stat := " f | 2 +-\n 1 file changed, 1 insertion(+), 1 deletion(-)\n\n"
+	if got := DiffPreamble(stat + patch); got != stat {
+		t.Errorf("preamble = %q, want %q", got, stat)
+	}
+	if got := DiffPreamble(stat); got != "" {
+		t.Errorf("stat only preamble = %q", got)
+	}
+}
+
+func TestFilterDiffAndSelectHunk(t *testing.T) {
+	files := []FileDiff{
+		{NewPath: "src/a.go", Hunks: []Hunk{{NewStart: 1}, {NewStart: 9}}},
+		{NewPath: "src/a_test.go", Hunks: []Hunk{{NewStart: 1}}},
+		{NewPath: "docs/a.md", Hunks: []Hunk{{NewStart: 1}}},
+	}
+	paths := func(fs []FileDiff) []string {
+		var out []string
+		for _, f := range fs {
+			out = append(out, f.Path())
+		}
+		return out
+	}
+
+	if got := paths(FilterDiff(files, "*.go", "*_test.go")); !reflect.DeepEqual(got, []string{"src/a.go"}) {
+		t.Errorf("FilterDiff(*.go, !*_test.go) = %v", got)
+	}
+	if got := paths(FilterDiff(files, "**/*.md", "")); !reflect.DeepEqual(got, []string{"docs/a.md"}) {
+		t.Errorf("FilterDiff(**/*.md) = %v", got)
+	}
+	if got := paths(FilterDiff(files, "src/*", "")); len(got) != 2 {
+		t.Errorf("FilterDiff(src/*) = %v", got)
+	}
+
+	sel, err := SelectHunk(files, "a.go:2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sel) != 1 || len(sel[0].Hunks) != 1 || sel[0].Hunks[0].NewStart != 9 {
+		t.Errorf("SelectHunk = %+v", sel)
+	}
+	for _, spec := range []string{"a.go:3", "a.go", "a.go:0", "b.go:1"} {
+		if _, err := SelectHunk(files, spec); err == nil {
+			t.Errorf("SelectHunk(%q): want error", spec)
+		}
+	}
+}
+
+func TestSinceArg(t *testing.T) {
+	tests := map[string]string{
+		"90d":        "--since=90.days.ago",
+		"12w":        "--since=12.weeks.ago",
+		"6m":         "--since=6.months.ago",
+		"1y":         "--since=1.years.ago",
+		"2024-01-01": "--since=2024-01-01",
+	}
+	for in, want := range tests {
+		if got := SinceArg(in); got != want {
+			t.Errorf("SinceArg(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestChurnAndLogDiffs(t *testing.T) {
+	dir := setupGitRepo(t)
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	for _, content := range []string{"hello\nworld\n", "hello\nthere\nworld\n"} {
+		os.WriteFile("file.txt", []byte(content), 0644)
+		if out, err := exec.Command("git", "commit", "-qam", "edit").CombinedOutput(); err != nil {
+			t.Fatalf("commit: %s: %v", out, err)
+		}
+	}
+
+	churn, err := Churn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fc := churn["file.txt"]
+	if fc == nil || fc.Commits != 3 || fc.Added != 3 || fc.Deleted != 0 {
+		t.Errorf("churn = %+v", fc)
+	}
+
+	commits, err := LogDiffs("-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 1 || len(commits[0].Files) != 1 {
+		t.Fatalf("LogDiffs = %+v", commits)
+	}
+	if start, end := commits[0].Files[0].Hunks[0].NewRange(); start != 2 || end != 2 {
+		t.Errorf("range = %d-%d, want 2-2", start, end)
+	}
+}
+
+func TestDiffFromAndShow(t *testing.T) {
+	dir := setupGitRepo(t)
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	os.WriteFile("file.txt", []byte("hello\nworld\n"), 0644)
+	files, err := DiffFrom("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Path() != "file.txt" || len(files[0].Hunks) != 1 {
+		t.Fatalf("DiffFrom = %+v", files)
+	}
+	if start, end := files[0].Hunks[0].OldRange(); start != 1 || end != 1 {
+		t.Errorf("old range = %d-%d, want 1-1", start, end)
+	}
+
+	old, err := Show("HEAD", "file.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if old != "hello\n" {
+		t.Errorf("Show = %q, want %q", old, "hello\n")
+	}
+}
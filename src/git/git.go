@@ -16,14 +16,94 @@ func MergeBase(base string) (string, error) {
 	return strings.TrimSpace(r.Stdout), nil
 }
 
+// Toplevel returns the absolute path of the current repository's work tree.
+func Toplevel() (string, error) {
+	r, err := runner.Run([]string{"git", "rev-parse", "--show-toplevel"})
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(r.Stdout), nil
+}
+
+// ConfigAll returns every value of a git config key, or nil if it is unset.
+func ConfigAll(key string) []string {
+	r, err := runner.RunNoCheck([]string{"git", "config", "--get-all", key})
+	if err != nil || r.ExitCode != 0 {
+		return nil
+	}
+	var vals []string
+	for _, line := range strings.Split(strings.TrimSpace(r.Stdout), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			vals = append(vals, line)
+		}
+	}
+	return vals
+}
+
+// Commit is one entry of a one-line log.
+type Commit struct {
+	Hash    string `json:"hash"`
+	Date    string `json:"date"`
+	Author  string `json:"author"`
+	Subject string `json:"subject"`
+}
+
+// Log runs git log with extra args (revision ranges, filters) and parses one
+// Commit per entry.
+func Log(args ...string) ([]Commit, error) {
+	gitArgs := append([]string{"git", "log", "--format=%h%x1f%cs%x1f%an%x1f%s"}, args...)
+	r, err := runner.Run(gitArgs)
+	if err != nil {
+		return nil, err
+	}
+	var commits []Commit
+	for _, line := range strings.Split(r.Stdout, "\n") {
+		f := strings.Split(line, "\x1f")
+		if len(f) != 4 {
+			continue
+		}
+		commits = append(commits, Commit{Hash: f[0], Date: f[1], Author: f[2], Subject: f[3]})
+	}
+	return commits, nil
+}
+
+// CurrentBranch returns the checked-out branch name, empty when detached.
+func CurrentBranch() (string, error) {
+	r, err := runner.Run([]string{"git", "branch", "--show-current"})
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(r.Stdout), nil
+}
+
+// Branches lists local branch names, plus remote-tracking ones if remotes is set.
+func Branches(remotes bool) ([]string, error) {
+	args := []string{"git", "branch", "--format=%(refname:short)"}
+	if remotes {
+		args = append(args, "-a")
+	}
+	r, err := runner.Run(args)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, line := range strings.Split(r.Stdout, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "(") {
+			names = append(names, line)
+		}
+	}
+	return names, nil
+}
+
 func Status(w io.Writer) error {
-	branch, err := runner.Run([]string{"git", "branch", "--show-current"})
+	branch, err := CurrentBranch()
 	if err != nil {
 		return err
 	}
 	status, _ := runner.RunNoCheck([]string{"git", "status", "--short"})
 
-	fmt.Fprintf(w, "Branch: %s\n", strings.TrimSpace(branch.Stdout))
+	fmt.Fprintf(w, "Branch: %s\n", branch)
 	fmt.Fprintln(w, "---")
 	fmt.Fprint(w, status.Stdout)
 	return nil
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -76,3 +77,73 @@ func TestStatus(t *testing.T) {
 		t.Errorf("output missing separator, got: %s", out)
 	}
 }
+
+func TestToplevel(t *testing.T) {
+	dir := setupGitRepo(t)
+	oldDir, _ := os.Getwd()
+	sub := dir + "/sub"
+	os.Mkdir(sub, 0755)
+	os.Chdir(sub)
+	defer os.Chdir(oldDir)
+
+	top, err := Toplevel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := filepath.EvalSymlinks(dir)
+	if got, _ := filepath.EvalSymlinks(top); got != want {
+		t.Errorf("Toplevel = %q, want %q", got, want)
+	}
+}
+
+func TestConfigAll(t *testing.T) {
+	dir := setupGitRepo(t)
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	if vals := ConfigAll("repotools.missing"); vals != nil {
+		t.Errorf("unset key = %v, want nil", vals)
+	}
+	exec.Command("git", "config", "--add", "repotools.multi", "a").Run()
+	exec.Command("git", "config", "--add", "repotools.multi", "b").Run()
+	vals := ConfigAll("repotools.multi")
+	if len(vals) != 2 || vals[0] != "a" || vals[1] != "b" {
+		t.Errorf("ConfigAll = %v, want [a b]", vals)
+	}
+}
+
+func TestLog(t *testing.T) {
+	dir := setupGitRepo(t)
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	commits, err := Log("-n", "5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 1 {
+		t.Fatalf("got %d commits, want 1", len(commits))
+	}
+	c := commits[0]
+	if c.Subject != "initial" || c.Author != "Test" || len(c.Hash) < 7 || len(c.Date) != 10 {
+		t.Errorf("commit = %+v", c)
+	}
+}
+
+func TestBranches(t *testing.T) {
+	dir := setupGitRepo(t)
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	exec.Command("git", "branch", "feature/T1-thing").Run()
+	names, err := Branches(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 || names[0] != "feature/T1-thing" || names[1] != "main" {
+		t.Errorf("Branches = %v", names)
+	}
+}
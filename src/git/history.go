@@ -0,0 +1,148 @@
+package git
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"repotools/src/output"
+	"repotools/src/runner"
+)
+
+// HistoryEntry is one commit of a file's or function's history.
+type HistoryEntry struct {
+	Commit
+	// Path is the file's path in the commit, "dir/{old => new}" for a
+	// rename.
+	Path    string `json:"path,omitempty"`
+	Added   int    `json:"added"`
+	Deleted int    `json:"deleted"`
+	// Patch is the change to the function's lines, for FnHistory.
+	Patch string `json:"patch,omitempty"`
+}
+
+const historyFormat = "--format=%x1e%h%x1f%cs%x1f%an%x1f%s"
+
+// FileHistory lists the commits touching path, newest first and following
+// renames, with lines added and deleted. It returns at most n entries (all
+// when n <= 0) and whether older ones were left out.
+func FileHistory(path string, n int) ([]HistoryEntry, bool, error) {
+	args := []string{"git", "log", "--follow", "--numstat", historyFormat}
+	return history(append(limitArg(args, n), "--", path), n)
+}
+
+// FnHistory lists the commits changing lines start..end of path (as they
+// are now) with `git log -L`, each with its patch.
+func FnHistory(path string, start, end, n int) ([]HistoryEntry, bool, error) {
+	args := []string{"git", "log", "--no-color", historyFormat, fmt.Sprintf("-L%d,%d:%s", start, end, path)}
+	return history(limitArg(args, n), n)
+}
+
+// limitArg asks for one commit more than n, to tell whether any were left
+// out.
+func limitArg(args []string, n int) []string {
+	if n <= 0 {
+		return args
+	}
+	return append(args, fmt.Sprintf("-n%d", n+1))
+}
+
+func history(args []string, n int) ([]HistoryEntry, bool, error) {
+	r, err := runner.RunNoCheck(args)
+	if err != nil {
+		return nil, false, err
+	}
+	if r.ExitCode != 0 {
+		return nil, false, fmt.Errorf("git log: %s", strings.TrimSpace(r.Stderr))
+	}
+	entries := parseHistory(r.Stdout)
+	if n > 0 && len(entries) > n {
+		return entries[:n], true, nil
+	}
+	return entries, false, nil
+}
+
+// parseHistory reads records of a historyFormat header followed by either
+// numstat lines or a patch.
+func parseHistory(out string) []HistoryEntry {
+	var entries []HistoryEntry
+	for _, record := range strings.Split(out, "\x1e") {
+		header, body, _ := strings.Cut(record, "\n")
+		f := strings.Split(header, "\x1f")
+		if len(f) != 4 {
+			continue
+		}
+		e := HistoryEntry{Commit: Commit{Hash: f[0], Date: f[1], Author: f[2], Subject: f[3]}}
+		body = strings.TrimLeft(body, "\n")
+		if strings.HasPrefix(body, "diff ") {
+			e.Patch = body
+			for _, f := range ParseDiff(body) {
+				e.Added += f.Added()
+				e.Deleted += f.Removed()
+			}
+		} else {
+			for _, line := range strings.Split(body, "\n") {
+				stat := strings.SplitN(line, "\t", 3)
+				if len(stat) != 3 {
+					continue
+				}
+				// Binary files show "-" counts, which stay zero.
+				added, _ := strconv.Atoi(stat[0])
+				deleted, _ := strconv.Atoi(stat[1])
+				e.Added += added
+				e.Deleted += deleted
+				e.Path = stat[2]
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// RootPath returns path relative to the repository root, the form git log
+// prints it in, or path itself when git does not track it as one file.
+func RootPath(path string) string {
+	r, err := runner.RunNoCheck([]string{"git", "ls-files", "--full-name", "--", path})
+	if err != nil || r.ExitCode != 0 {
+		return path
+	}
+	name, rest, _ := strings.Cut(r.Stdout, "\n")
+	if name == "" || rest != "" {
+		return path
+	}
+	return name
+}
+
+// WriteHistory prints one line per commit with its stats and, when the
+// file's historical path differs from the one asked for (path, relative to
+// the repository root), the name it had. Patches follow their commit, cut
+// to patchLines lines. more adds a marker for left-out older commits.
+func WriteHistory(w io.Writer, path string, entries []HistoryEntry, more bool, patchLines int, asJSON bool) error {
+	if asJSON {
+		return writeJSON(w, struct {
+			Commits []HistoryEntry `json:"commits"`
+			More    bool           `json:"more"`
+		}{nonNil(entries), more})
+	}
+	authorWidth := 0
+	for _, e := range entries {
+		authorWidth = max(authorWidth, len(e.Author))
+	}
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s %s  %-*s  +%d -%d  %s", e.Hash, e.Date, authorWidth, e.Author, e.Added, e.Deleted, e.Subject)
+		if e.Path != "" && e.Path != path {
+			fmt.Fprintf(w, "  (%s)", e.Path)
+		}
+		fmt.Fprintln(w)
+		if e.Patch != "" {
+			patch := strings.TrimRight(e.Patch, "\n") + "\n"
+			fmt.Fprint(w, output.TruncateLines(patch, patchLines, "--patch-lines"))
+			fmt.Fprintln(w)
+		}
+	}
+	if more {
+		fmt.Fprintln(w, "[older commits omitted, rerun with -n 0 for all]")
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package git
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseHistory(t *testing.T) {
+	numstat := "\x1eaaaaaaa\x1f2026-01-02\x1fAlice\x1fedit\n\n3\t1\td/b.go\n" +
+		"\x1ebbbbbbb\x1f2026-01-01\x1fBob\x1fmove\n\n0\t0\td/{a.go => b.go}\n" +
+		"\x1eccccccc\x1f2026-01-01\x1fBob\x1fbinary\n\n-\t-\td/a.go\n"
+	entries := parseHistory(numstat)
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	if e := entries[0]; e.Hash != "aaaaaaa" || e.Added != 3 || e.Deleted != 1 || e.Path != "d/b.go" || e.Patch != "" {
+		t.Errorf("edit = %+v", e)
+	}
+	if e := entries[1]; e.Path != "d/{a.go => b.go}" || e.Author != "Bob" {
+		t.Errorf("move = %+v", e)
+	}
+	if e := entries[2]; e.Added != 0 || e.Deleted != 0 {
+		t.Errorf("binary = %+v", e)
+	}
+
+	patch := "\x1eaaaaaaa\x1f2026-01-02\x1fAlice\x1fedit\n\ndiff --git a/f b/f\n--- a/f\n+++ b/f\n@@ -1,2 +1,2 @@\n-old\n+new\n+more\n ctx\n"
+	entries = parseHistory(patch)
+	if len(entries) != 1 || entries[0].Added != 2 || entries[0].Deleted != 1 || !strings.HasPrefix(entries[0].Patch, "diff --git") {
+		t.Errorf("patch entries = %+v", entries)
+	}
+
+	// Changed lines that look like file headers still count.
+	patch = "\x1eaaaaaaa\x1f2026-01-02\x1fAlice\x1fedit\n\ndiff --git a/f b/f\n--- a/f\n+++ b/f\n@@ -1 +1 @@\n--- old\n+++ new\n"
+	entries = parseHistory(patch)
+	if len(entries) != 1 || entries[0].Added != 1 || entries[0].Deleted != 1 {
+		t.Errorf("header-like patch entries = %+v", entries)
+	}
+}
+
+func TestFileAndFnHistory(t *testing.T) {
+	dir := setupGitRepo(t)
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s: %v", args, out, err)
+		}
+	}
+	os.WriteFile("a.go", []byte("package x\n\nfunc A() {\n\treturn\n}\n"), 0644)
+	git("add", ".")
+	git("commit", "-qm", "add a")
+	git("mv", "a.go", "b.go")
+	git("commit", "-qm", "rename")
+	os.WriteFile(filepath.Join(dir, "b.go"), []byte("package x\n\nfunc A() {\n\tprintln()\n\treturn\n}\n"), 0644)
+	git("commit", "-qam", "edit A")
+
+	entries, more, err := FileHistory("b.go", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if more || len(entries) != 3 || entries[0].Subject != "edit A" || entries[2].Path != "a.go" || entries[2].Added != 5 {
+		t.Errorf("FileHistory = %+v, more %v", entries, more)
+	}
+
+	entries, more, err = FileHistory("b.go", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !more || len(entries) != 2 {
+		t.Errorf("FileHistory(n=2) = %d entries, more %v", len(entries), more)
+	}
+
+	entries, _, err = FnHistory("b.go", 3, 6, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Subject != "edit A" || entries[0].Added != 1 || entries[1].Subject != "add a" {
+		t.Fatalf("FnHistory = %+v", entries)
+	}
+
+	var buf bytes.Buffer
+	WriteHistory(&buf, "b.go", entries, true, 3, false)
+	out := buf.String()
+	for _, want := range []string{"edit A", "rerun with --patch-lines=", "[older commits omitted"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("WriteHistory missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestFileHistory_Subdir(t *testing.T) {
+	dir := setupGitRepo(t)
+	oldDir, _ := os.Getwd()
+	defer os.Chdir(oldDir)
+
+	os.Mkdir(filepath.Join(dir, "sub"), 0755)
+	os.WriteFile(filepath.Join(dir, "sub", "c.go"), []byte("package sub\n"), 0644)
+	for _, args := range [][]string{{"add", "."}, {"commit", "-qm", "add c"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s: %v", args, out, err)
+		}
+	}
+	os.Chdir(filepath.Join(dir, "sub"))
+
+	if got := RootPath("c.go"); got != "sub/c.go" {
+		t.Errorf("RootPath(c.go) = %q, want sub/c.go", got)
+	}
+	entries, _, err := FileHistory("c.go", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	WriteHistory(&buf, RootPath("c.go"), entries, false, 0, false)
+	if out := buf.String(); !strings.Contains(out, "add c") || strings.Contains(out, "(sub/c.go)") {
+		t.Errorf("WriteHistory from a subdirectory:\n%s", out)
+	}
+}
@@ -0,0 +1,159 @@
+package git
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteLog prints commits as "hash subject" lines, or as a JSON array.
+func WriteLog(w io.Writer, commits []Commit, asJSON bool) error {
+	if asJSON {
+		return writeJSON(w, nonNil(commits))
+	}
+	for _, c := range commits {
+		fmt.Fprintf(w, "%s %s\n", c.Hash, c.Subject)
+	}
+	return nil
+}
+
+// DiffOptions shapes WriteDiff output.
+type DiffOptions struct {
+	JSON bool
+	// StatFirst prints a per-file summary before the patches.
+	StatFirst bool
+}
+
+// WriteDiff prints files as a patch, optionally after a stat summary, or
+// as a JSON array.
+func WriteDiff(w io.Writer, files []FileDiff, opts DiffOptions) error {
+	if opts.JSON {
+		return writeJSON(w, nonNil(files))
+	}
+	if opts.StatFirst {
+		WriteDiffStat(w, files)
+		if len(files) > 0 {
+			fmt.Fprintln(w)
+		}
+	}
+	WritePatch(w, files)
+	return nil
+}
+
+// WritePatch prints files as a unified diff. Hunk headers name Function
+// instead of git's context when it is set.
+func WritePatch(w io.Writer, files []FileDiff) {
+	for _, f := range files {
+		for _, line := range f.Header {
+			fmt.Fprintln(w, line)
+		}
+		for _, h := range f.Hunks {
+			header := h.Header
+			if h.Function != "" {
+				header = h.Function
+			}
+			fmt.Fprintf(w, "@@ -%s +%s @@", formatRange(h.OldStart, h.OldLines), formatRange(h.NewStart, h.NewLines))
+			if header != "" {
+				fmt.Fprint(w, " "+header)
+			}
+			fmt.Fprintln(w)
+			for _, l := range h.Lines {
+				if l.Op == "\\" {
+					fmt.Fprintf(w, "\\ %s\n", l.Text)
+					continue
+				}
+				fmt.Fprintf(w, "%s%s\n", l.Op, l.Text)
+			}
+		}
+	}
+}
+
+// formatRange renders a hunk range as git does, leaving out a count of 1.
+func formatRange(start, count int) string {
+	if count == 1 {
+		return strconv.Itoa(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// statWidth is the widest +/- bar of WriteDiffStat.
+const statWidth = 40
+
+// WriteDiffStat prints a git-style summary: one " path | N ++--" line per
+// file and a total.
+func WriteDiffStat(w io.Writer, files []FileDiff) {
+	nameWidth, most := 0, 0
+	for _, f := range files {
+		nameWidth = max(nameWidth, len(f.Path()))
+		most = max(most, f.Added()+f.Removed())
+	}
+	added, removed := 0, 0
+	for _, f := range files {
+		if f.Binary {
+			fmt.Fprintf(w, " %-*s | Bin\n", nameWidth, f.Path())
+			continue
+		}
+		a, r := f.Added(), f.Removed()
+		added += a
+		removed += r
+		plus, minus := a, r
+		if most > statWidth {
+			// Scale bars down, keeping at least one mark per non-zero side.
+			plus, minus = scaleBar(a, most), scaleBar(r, most)
+		}
+		fmt.Fprintf(w, " %-*s | %4d %s%s\n", nameWidth, f.Path(), a+r, strings.Repeat("+", plus), strings.Repeat("-", minus))
+	}
+	fmt.Fprintf(w, " %d %s changed, %d %s(+), %d %s(-)\n",
+		len(files), plural(len(files), "file", "files"),
+		added, plural(added, "insertion", "insertions"),
+		removed, plural(removed, "deletion", "deletions"))
+}
+
+func scaleBar(n, most int) int {
+	if n == 0 {
+		return 0
+	}
+	return max(n*statWidth/most, 1)
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+// WriteTree prints one path per tree entry, or the entries as a JSON array.
+// Entries listed with sizes (ls-tree -l) print every column as git does.
+func WriteTree(w io.Writer, entries []TreeEntry, asJSON bool) error {
+	if asJSON {
+		return writeJSON(w, nonNil(entries))
+	}
+	for _, e := range entries {
+		if e.Size != "" {
+			fmt.Fprintf(w, "%s %s %s %7s\t%s\n", e.Mode, e.Type, e.Hash, e.Size, e.Path)
+			continue
+		}
+		fmt.Fprintln(w, e.Path)
+	}
+	return nil
+}
+
+func writeJSON(w io.Writer, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+// nonNil makes empty results encode as [] rather than null.
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
@@ -0,0 +1,115 @@
+package git
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+func TestWriteLog(t *testing.T) {
+	commits := []Commit{{Hash: "abc1234", Date: "2024-01-02", Author: "A", Subject: "Fix bug"}}
+
+	var buf bytes.Buffer
+	WriteLog(&buf, commits, false)
+	if got := buf.String(); got != "abc1234 Fix bug\n" {
+		t.Errorf("text = %q", got)
+	}
+
+	buf.Reset()
+	WriteLog(&buf, commits, true)
+	var decoded []map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	if len(decoded) != 1 || decoded[0]["subject"] != "Fix bug" || decoded[0]["date"] != "2024-01-02" {
+		t.Errorf("json = %v", decoded)
+	}
+
+	buf.Reset()
+	WriteLog(&buf, nil, true)
+	if got := buf.String(); got != "[]\n" {
+		t.Errorf("empty json = %q, want []", got)
+	}
+}
+
+func TestWriteTree(t *testing.T) {
+	var buf bytes.Buffer
+	WriteTree(&buf, []TreeEntry{{Path: "a.go"}, {Path: "dir"}}, false)
+	if got := buf.String(); got != "a.go\ndir\n" {
+		t.Errorf("text = %q", got)
+	}
+
+	buf.Reset()
+	WriteTree(&buf, []TreeEntry{{Mode: "100644", Type: "blob", Hash: "abc", Size: "12", Path: "a.go"}}, false)
+	if got := buf.String(); got != "100644 blob abc      12\ta.go\n" {
+		t.Errorf("long text = %q", got)
+	}
+}
+
+func TestWriteDiff_StatFirstAndFunction(t *testing.T) {
+	files := []FileDiff{{
+		OldPath: "a.go", NewPath: "a.go",
+		Header: []string{"diff --git a/a.go b/a.go", "--- a/a.go", "+++ b/a.go"},
+		Hunks: []Hunk{{
+			OldStart: 3, OldLines: 1, NewStart: 3, NewLines: 2, Header: "git context", Function: "func run() {",
+			Lines: []Line{{Op: "-", Text: "x"}, {Op: "+", Text: "y"}, {Op: "+", Text: "z"}},
+		}},
+	}, {
+		OldPath: "img.png", NewPath: "img.png", Binary: true,
+		Header: []string{"diff --git a/img.png b/img.png", "Binary files a/img.png and b/img.png differ"},
+	}}
+
+	var buf bytes.Buffer
+	WriteDiff(&buf, files, DiffOptions{StatFirst: true})
+	want := ` a.go    |    3 ++-
+ img.png | Bin
+ 2 files changed, 2 insertions(+), 1 deletion(-)
+
+diff --git a/a.go b/a.go
+--- a/a.go
++++ b/a.go
+@@ -3 +3,2 @@ func run() {
+-x
++y
++z
+diff --git a/img.png b/img.png
+Binary files a/img.png and b/img.png differ
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDiffAndWriteDiff(t *testing.T) {
+	dir := setupGitRepo(t)
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	os.WriteFile("file.txt", []byte("hello\nworld\n"), 0644)
+	patch, err := Diff("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	WritePatch(&buf, ParseDiff(patch))
+	if buf.String() != patch {
+		t.Errorf("text output should be the patch as is:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	WriteDiff(&buf, ParseDiff(patch), DiffOptions{JSON: true})
+	var files []FileDiff
+	if err := json.Unmarshal(buf.Bytes(), &files); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	if len(files) != 1 || files[0].Path() != "file.txt" || len(files[0].Hunks) != 1 || files[0].Hunks[0].NewLines != 2 {
+		t.Errorf("json files = %+v", files)
+	}
+
+	if _, err := Diff("no-such-rev"); err == nil {
+		t.Error("bad revision: want error")
+	}
+}
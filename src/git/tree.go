@@ -0,0 +1,48 @@
+package git
+
+import (
+	"strings"
+
+	"repotools/src/runner"
+)
+
+// TreeEntry is one entry of git ls-tree.
+type TreeEntry struct {
+	Mode string `json:"mode"`
+	Type string `json:"type"`
+	Hash string `json:"hash"`
+	// Size is the blob size in bytes ("-" for trees), set with -l.
+	Size string `json:"size,omitempty"`
+	Path string `json:"path"`
+}
+
+// LsTree lists the entries of the tree rev with extra ls-tree args
+// (-r, -l, "--" paths). With --name-only among args only Path is set.
+func LsTree(rev string, args ...string) ([]TreeEntry, error) {
+	gitArgs := append([]string{"git", "ls-tree", "-z", rev}, args...)
+	r, err := runner.Run(gitArgs)
+	if err != nil {
+		return nil, err
+	}
+	var entries []TreeEntry
+	for _, rec := range strings.Split(r.Stdout, "\x00") {
+		if rec == "" {
+			continue
+		}
+		meta, path, ok := strings.Cut(rec, "\t")
+		if !ok {
+			entries = append(entries, TreeEntry{Path: rec})
+			continue
+		}
+		e := TreeEntry{Path: path}
+		f := strings.Fields(meta)
+		if len(f) >= 3 {
+			e.Mode, e.Type, e.Hash = f[0], f[1], f[2]
+		}
+		if len(f) >= 4 {
+			e.Size = f[3]
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
@@ -0,0 +1,51 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestLsTree(t *testing.T) {
+	dir := setupGitRepo(t)
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	os.Mkdir("sub", 0755)
+	os.WriteFile("sub/a b.txt", []byte("x\n"), 0644)
+	exec.Command("git", "add", ".").Run()
+	exec.Command("git", "commit", "-m", "add sub").Run()
+
+	entries, err := LsTree("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Path != "file.txt" || entries[0].Type != "blob" || entries[1].Path != "sub" || entries[1].Type != "tree" {
+		t.Fatalf("LsTree = %+v", entries)
+	}
+
+	entries, err = LsTree("HEAD", "-r", "--", "sub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Path != "sub/a b.txt" || len(entries[0].Hash) != 40 {
+		t.Errorf("LsTree -r sub = %+v", entries)
+	}
+
+	entries, err = LsTree("HEAD", "--name-only")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[1].Path != "sub" || entries[1].Hash != "" {
+		t.Errorf("LsTree --name-only = %+v", entries)
+	}
+
+	entries, err = LsTree("HEAD", "-l")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Size == "" || entries[1].Size != "-" {
+		t.Errorf("LsTree -l = %+v", entries)
+	}
+}
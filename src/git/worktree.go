@@ -0,0 +1,209 @@
+package git
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"repotools/src/runner"
+)
+
+// Worktree is one working tree of the repository.
+type Worktree struct {
+	Path string `json:"path"`
+	Head string `json:"head"`
+	// Branch is the checked-out branch, empty when detached or bare.
+	Branch   string `json:"branch,omitempty"`
+	Bare     bool   `json:"bare,omitempty"`
+	Locked   bool   `json:"locked,omitempty"`
+	Prunable bool   `json:"prunable,omitempty"`
+}
+
+// Worktrees lists the working trees of the current repository, main first.
+func Worktrees() ([]Worktree, error) {
+	r, err := runner.Run([]string{"git", "worktree", "list", "--porcelain"})
+	if err != nil {
+		return nil, err
+	}
+	return parseWorktrees(r.Stdout), nil
+}
+
+func parseWorktrees(out string) []Worktree {
+	var wts []Worktree
+	var cur *Worktree
+	for _, line := range strings.Split(out, "\n") {
+		key, val, _ := strings.Cut(line, " ")
+		switch {
+		case key == "worktree":
+			wts = append(wts, Worktree{Path: val})
+			cur = &wts[len(wts)-1]
+		case cur == nil:
+		case key == "HEAD":
+			cur.Head = val
+		case key == "branch":
+			cur.Branch = strings.TrimPrefix(val, "refs/heads/")
+		case key == "bare":
+			cur.Bare = true
+		case key == "locked":
+			cur.Locked = true
+		case key == "prunable":
+			cur.Prunable = true
+		}
+	}
+	return wts
+}
+
+// FindWorktree resolves a worktree by branch name, directory name or path.
+func FindWorktree(name string) (Worktree, error) {
+	wts, err := Worktrees()
+	if err != nil {
+		return Worktree{}, err
+	}
+	abs, _ := filepath.Abs(name)
+	for _, match := range []func(Worktree) bool{
+		func(wt Worktree) bool { return wt.Branch == name },
+		func(wt Worktree) bool { return wt.Path == abs },
+		func(wt Worktree) bool { return filepath.Base(wt.Path) == name },
+	} {
+		for _, wt := range wts {
+			if match(wt) {
+				return wt, nil
+			}
+		}
+	}
+	var names []string
+	for _, wt := range wts {
+		if wt.Branch != "" {
+			names = append(names, wt.Branch)
+		}
+	}
+	return Worktree{}, fmt.Errorf("no worktree for %q (branches: %s)", name, strings.Join(names, ", "))
+}
+
+// AheadBehind counts the commits of rev not in base (ahead) and of base not
+// in rev (behind).
+func AheadBehind(base, rev string) (ahead, behind int, err error) {
+	r, err := runner.Run([]string{"git", "rev-list", "--left-right", "--count", base + "..." + rev})
+	if err != nil {
+		return 0, 0, err
+	}
+	f := strings.Fields(r.Stdout)
+	if len(f) != 2 {
+		return 0, 0, fmt.Errorf("unexpected rev-list output %q", r.Stdout)
+	}
+	behind, _ = strconv.Atoi(f[0])
+	ahead, _ = strconv.Atoi(f[1])
+	return ahead, behind, nil
+}
+
+// ChangedFiles counts the modified and untracked files of the working tree
+// at dir.
+func ChangedFiles(dir string) (int, error) {
+	r, err := runner.Run([]string{"git", "-C", dir, "status", "--porcelain"})
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, line := range strings.Split(r.Stdout, "\n") {
+		if line != "" {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// WorktreeStatus is a worktree with its state relative to a base branch.
+type WorktreeStatus struct {
+	Worktree
+	Current bool   `json:"current,omitempty"`
+	Changed int    `json:"changed"`
+	Ahead   int    `json:"ahead"`
+	Behind  int    `json:"behind"`
+	Last    Commit `json:"last"`
+}
+
+// WorktreeStatuses describes every worktree: dirty files, commits ahead of
+// and behind base, and the last commit.
+func WorktreeStatuses(base string) ([]WorktreeStatus, error) {
+	wts, err := Worktrees()
+	if err != nil {
+		return nil, err
+	}
+	top, _ := Toplevel()
+	var out []WorktreeStatus
+	for _, wt := range wts {
+		st := WorktreeStatus{Worktree: wt, Current: sameDir(wt.Path, top)}
+		if wt.Bare || wt.Prunable {
+			out = append(out, st)
+			continue
+		}
+		if st.Changed, err = ChangedFiles(wt.Path); err != nil {
+			return nil, err
+		}
+		if wt.Head != "" {
+			// A base that does not exist leaves the counts at zero.
+			st.Ahead, st.Behind, _ = AheadBehind(base, wt.Head)
+			if commits, err := Log("-1", wt.Head); err == nil && len(commits) == 1 {
+				st.Last = commits[0]
+			}
+		}
+		out = append(out, st)
+	}
+	return out, nil
+}
+
+func sameDir(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	ia, errA := os.Stat(a)
+	ib, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(ia, ib)
+}
+
+// WriteWorktrees prints one line per worktree: a * for the current one,
+// branch, path, changed files, ahead/behind base and the last commit.
+func WriteWorktrees(w io.Writer, statuses []WorktreeStatus, asJSON bool) error {
+	if asJSON {
+		return writeJSON(w, nonNil(statuses))
+	}
+	branchWidth, pathWidth := 0, 0
+	for _, st := range statuses {
+		branchWidth = max(branchWidth, len(worktreeBranch(st.Worktree)))
+		pathWidth = max(pathWidth, len(st.Path))
+	}
+	for _, st := range statuses {
+		mark := " "
+		if st.Current {
+			mark = "*"
+		}
+		state := "clean"
+		switch {
+		case st.Bare:
+			state = "bare"
+		case st.Prunable:
+			state = "prunable"
+		case st.Changed > 0:
+			state = fmt.Sprintf("%d changed", st.Changed)
+		}
+		fmt.Fprintf(w, "%s %-*s  %-*s  %-10s  +%d -%d", mark, branchWidth, worktreeBranch(st.Worktree), pathWidth, st.Path, state, st.Ahead, st.Behind)
+		if st.Last.Hash != "" {
+			fmt.Fprintf(w, "  %s %s %s", st.Last.Hash, st.Last.Date, st.Last.Subject)
+		}
+		fmt.Fprintln(w)
+	}
+	return nil
+}
+
+func worktreeBranch(wt Worktree) string {
+	switch {
+	case wt.Branch != "":
+		return wt.Branch
+	case wt.Bare:
+		return "(bare)"
+	}
+	return "(detached)"
+}
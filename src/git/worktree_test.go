@@ -0,0 +1,97 @@
+package git
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseWorktrees(t *testing.T) {
+	out := `worktree /repo
+HEAD 1111111111111111111111111111111111111111
+branch refs/heads/main
+
+worktree /repo-feature
+HEAD 2222222222222222222222222222222222222222
+branch refs/heads/feature/x
+locked
+
+worktree /repo-detached
+HEAD 3333333333333333333333333333333333333333
+detached
+prunable gitdir file points to non-existent location
+
+`
+	wts := parseWorktrees(out)
+	if len(wts) != 3 {
+		t.Fatalf("got %d worktrees, want 3: %+v", len(wts), wts)
+	}
+	if wts[0].Path != "/repo" || wts[0].Branch != "main" || wts[0].Head[0] != '1' {
+		t.Errorf("main = %+v", wts[0])
+	}
+	if wts[1].Branch != "feature/x" || !wts[1].Locked {
+		t.Errorf("feature = %+v", wts[1])
+	}
+	if wts[2].Branch != "" || !wts[2].Prunable {
+		t.Errorf("detached = %+v", wts[2])
+	}
+}
+
+func TestWorktreeStatuses(t *testing.T) {
+	dir := setupGitRepo(t)
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	feature := filepath.Join(t.TempDir(), "feature-wt")
+	run := func(dir string, args ...string) {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("%v: %s: %v", args, out, err)
+		}
+	}
+	run(dir, "git", "worktree", "add", "-q", "-b", "feature", feature)
+	os.WriteFile(filepath.Join(feature, "new.txt"), []byte("x\n"), 0644)
+	run(feature, "git", "add", ".")
+	run(feature, "git", "commit", "-qm", "feature work")
+	os.WriteFile(filepath.Join(feature, "file.txt"), []byte("changed\n"), 0644)
+
+	for _, name := range []string{"feature", "feature-wt", feature} {
+		wt, err := FindWorktree(name)
+		if err != nil {
+			t.Fatalf("FindWorktree(%q): %v", name, err)
+		}
+		if wt.Branch != "feature" {
+			t.Errorf("FindWorktree(%q) = %+v", name, wt)
+		}
+	}
+	if _, err := FindWorktree("nope"); err == nil || !strings.Contains(err.Error(), "main, feature") {
+		t.Errorf("FindWorktree(nope) error = %v", err)
+	}
+
+	statuses, err := WorktreeStatuses("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 2 {
+		t.Fatalf("got %d statuses, want 2", len(statuses))
+	}
+	main, feat := statuses[0], statuses[1]
+	if !main.Current || main.Changed != 0 || main.Ahead != 0 || main.Last.Subject != "initial" {
+		t.Errorf("main = %+v", main)
+	}
+	if feat.Current || feat.Changed != 1 || feat.Ahead != 1 || feat.Behind != 0 || feat.Last.Subject != "feature work" {
+		t.Errorf("feature = %+v", feat)
+	}
+
+	var buf bytes.Buffer
+	WriteWorktrees(&buf, statuses, false)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "* main") || !strings.Contains(lines[1], "1 changed") || !strings.Contains(lines[1], "+1 -0") {
+		t.Errorf("WriteWorktrees =\n%s", buf.String())
+	}
+}
@@ -3,8 +3,10 @@ package github
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 
+	"repotools/src/output"
 	"repotools/src/runner"
 )
 
@@ -110,6 +112,57 @@ func FetchReviewComments(repo string, prNumber int) ([]ReviewComment, error) {
 	return comments, nil
 }
 
+// ListPRs runs `gh pr list` across all states with extra args (e.g. --search,
+// --head) and returns the matching PR summaries.
+func ListPRs(extra ...string) ([]PRSummary, error) {
+	return listPRs("number,title,body,state,headRefName,url", extra...)
+}
+
+func listPRs(fields string, extra ...string) ([]PRSummary, error) {
+	args := append([]string{"gh", "pr", "list", "--state", "all", "--json", fields}, extra...)
+	r, err := runner.RunNoCheck(args)
+	if err != nil {
+		return nil, err
+	}
+	if r.ExitCode != 0 {
+		msg := strings.TrimSpace(r.Stderr)
+		if msg == "" {
+			msg = "failed to list PRs"
+		}
+		return nil, fmt.Errorf("%s", msg)
+	}
+
+	var prs []PRSummary
+	if err := json.Unmarshal([]byte(r.Stdout), &prs); err != nil {
+		return nil, fmt.Errorf("parsing PR list JSON: %w", err)
+	}
+	return prs, nil
+}
+
+// headPRLimit caps how many recent PRs HeadPRs looks through.
+const headPRLimit = 1000
+
+// HeadPRs maps each head branch to its newest PR, with one gh call for all
+// branches. Only Number, State and HeadRefName are set.
+func HeadPRs() (map[string]PRSummary, error) {
+	prs, err := listPRs("number,state,headRefName", "--limit", strconv.Itoa(headPRLimit))
+	if err != nil {
+		return nil, err
+	}
+	return newestByHead(prs), nil
+}
+
+// newestByHead keeps the first PR per head branch; gh lists newest first.
+func newestByHead(prs []PRSummary) map[string]PRSummary {
+	byHead := make(map[string]PRSummary)
+	for _, pr := range prs {
+		if _, ok := byHead[pr.HeadRefName]; !ok {
+			byHead[pr.HeadRefName] = pr
+		}
+	}
+	return byHead
+}
+
 func GetRepoNWO() (string, error) {
 	r, err := runner.Run([]string{"gh", "repo", "view", "--json", "nameWithOwner", "--jq", ".nameWithOwner"})
 	if err != nil {
@@ -119,6 +172,12 @@ func GetRepoNWO() (string, error) {
 }
 
 func RenderPR(data PRData, sections []string, reviewComments []ReviewComment) string {
+	return RenderPRBudget(data, sections, reviewComments, output.Budget{})
+}
+
+// RenderPRBudget is RenderPR with the budget shared fairly between the
+// sections, so a long comment thread does not push out the checks or files.
+func RenderPRBudget(data PRData, sections []string, reviewComments []ReviewComment, b output.Budget) string {
 	type sectionDef struct {
 		title    string
 		renderer func() string
@@ -135,11 +194,22 @@ func RenderPR(data PRData, sections []string, reviewComments []ReviewComment) st
 		"commits":         {"Commits", func() string { return RenderCommits(data) }},
 	}
 
+	bodies := make([]string, len(sections))
+	var frame strings.Builder
+	fmt.Fprintf(&frame, "# PR #%d: %s", data.Number, data.Title)
+	for i, s := range sections {
+		def := renderers[s]
+		bodies[i] = def.renderer()
+		fmt.Fprintf(&frame, "\n\n## %s\n\n", def.title)
+	}
+	if b.Limited() {
+		bodies = output.TruncateSections(bodies, b, frame.String())
+	}
+
 	var sb strings.Builder
 	fmt.Fprintf(&sb, "# PR #%d: %s", data.Number, data.Title)
-	for _, s := range sections {
-		def := renderers[s]
-		fmt.Fprintf(&sb, "\n\n## %s\n\n%s", def.title, def.renderer())
+	for i, s := range sections {
+		fmt.Fprintf(&sb, "\n\n## %s\n\n%s", renderers[s].title, bodies[i])
 	}
 	return sb.String()
 }
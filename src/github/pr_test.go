@@ -5,6 +5,8 @@ import (
 	"os"
 	"strings"
 	"testing"
+
+	"repotools/src/output"
 )
 
 func TestParsePRData(t *testing.T) {
@@ -69,3 +71,38 @@ func TestRenderPR_AllSections(t *testing.T) {
 		t.Errorf("missing Description section in:\n%s", out)
 	}
 }
+
+func TestRenderPRBudget(t *testing.T) {
+	data, _ := os.ReadFile("../../testdata/fixtures/pr.json")
+	var pr PRData
+	json.Unmarshal(data, &pr)
+	pr.Body = strings.Repeat("long description line\n", 200)
+
+	full := RenderPR(pr, AllSections, nil)
+	out := RenderPRBudget(pr, AllSections, nil, output.Budget{MaxLines: 60})
+	if n := strings.Count(out, "\n") + 1; n > 60 {
+		t.Errorf("got %d lines, want <= 60", n)
+	}
+	for _, title := range []string{"## Info", "## Description", "## Checks", "## Commits"} {
+		if !strings.Contains(out, title) {
+			t.Errorf("missing %s after truncation", title)
+		}
+	}
+	if !strings.Contains(out, "more lines omitted, rerun with --max-lines=") {
+		t.Errorf("missing omission marker in:\n%s", out)
+	}
+	if got := RenderPRBudget(pr, AllSections, nil, output.Budget{}); got != full {
+		t.Errorf("unlimited budget should match RenderPR")
+	}
+}
+
+func TestNewestByHead(t *testing.T) {
+	byHead := newestByHead([]PRSummary{
+		{Number: 9, State: "OPEN", HeadRefName: "feat"},
+		{Number: 4, State: "CLOSED", HeadRefName: "feat"},
+		{Number: 3, State: "MERGED", HeadRefName: "fix"},
+	})
+	if len(byHead) != 2 || byHead["feat"].Number != 9 || byHead["fix"].State != "MERGED" {
+		t.Errorf("newestByHead = %+v", byHead)
+	}
+}
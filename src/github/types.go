@@ -88,3 +88,12 @@ type ReviewComment struct {
 	DiffHunk     string `json:"diff_hunk"`
 	InReplyToID  *int   `json:"in_reply_to_id"`
 }
+
+type PRSummary struct {
+	Number      int    `json:"number"`
+	Title       string `json:"title"`
+	Body        string `json:"body"`
+	State       string `json:"state"`
+	HeadRefName string `json:"headRefName"`
+	URL         string `json:"url"`
+}
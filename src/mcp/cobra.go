@@ -0,0 +1,235 @@
+package mcp
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// SkipAnnotation marks a command that is not exposed as a tool, or a flag
+// (set with Flags().SetAnnotation) that is not exposed as a tool argument,
+// such as one confirming a destructive action.
+const SkipAnnotation = "mcp-skip"
+
+// argsProperty is the tool argument holding positional arguments.
+const argsProperty = "args"
+
+// CommandTools describes every runnable, visible command under root as a
+// tool named by its path ("tk-status", "tk-history"), with one property per
+// flag and an "args" array for positional arguments.
+func CommandTools(root *cobra.Command) []Tool {
+	var tools []Tool
+	var walk func(c *cobra.Command)
+	walk = func(c *cobra.Command) {
+		for _, sub := range c.Commands() {
+			if sub.Hidden || sub.Name() == "help" || sub.Name() == "completion" || sub.Annotations[SkipAnnotation] != "" {
+				continue
+			}
+			if sub.Runnable() {
+				tools = append(tools, commandTool(sub))
+			}
+			walk(sub)
+		}
+	}
+	walk(root)
+	return tools
+}
+
+// ToolName is the tool name of a command: its path below the root joined
+// with "-".
+func ToolName(c *cobra.Command) string {
+	return strings.Join(strings.Fields(c.CommandPath())[1:], "-")
+}
+
+func commandTool(c *cobra.Command) Tool {
+	props := map[string]any{}
+	addFlag := func(f *pflag.Flag) {
+		if f.Name == "help" || f.Hidden || skippedFlag(f) {
+			return
+		}
+		prop := map[string]any{"description": f.Usage}
+		switch f.Value.Type() {
+		case "bool":
+			prop["type"] = "boolean"
+		case "int", "int32", "int64", "uint", "count":
+			prop["type"] = "integer"
+		case "float32", "float64":
+			prop["type"] = "number"
+		case "stringArray", "stringSlice":
+			prop["type"] = "array"
+			prop["items"] = map[string]any{"type": "string"}
+		default:
+			prop["type"] = "string"
+		}
+		if f.DefValue != "" && f.DefValue != "[]" && f.DefValue != "false" && f.DefValue != "0" {
+			prop["default"] = f.DefValue
+		}
+		props[f.Name] = prop
+	}
+	// Commands that parse their own flags take everything as args.
+	if !c.DisableFlagParsing {
+		c.NonInheritedFlags().VisitAll(addFlag)
+		c.InheritedFlags().VisitAll(addFlag)
+	}
+
+	usage := strings.TrimSpace(strings.TrimPrefix(c.Use, c.Name()))
+	argsDesc := "Positional arguments"
+	if usage != "" {
+		argsDesc += ": " + usage
+	}
+	if c.DisableFlagParsing {
+		argsDesc += " (passed through as-is, including flags)"
+	}
+	props[argsProperty] = map[string]any{
+		"type":        "array",
+		"items":       map[string]any{"type": "string"},
+		"description": argsDesc,
+	}
+
+	desc := c.Short
+	if c.Long != "" {
+		desc = c.Long
+	}
+	return Tool{
+		Name:        ToolName(c),
+		Description: desc,
+		InputSchema: map[string]any{"type": "object", "properties": props},
+	}
+}
+
+// CommandCaller runs a tool by building a fresh command tree with newRoot,
+// executing the matching command with output captured, and restoring the
+// working directory afterwards (commands may chdir via -C).
+func CommandCaller(newRoot func() *cobra.Command) CallFunc {
+	return func(name string, args map[string]any) (string, error) {
+		root := newRoot()
+		c := findTool(root, name)
+		if c == nil {
+			return "", fmt.Errorf("unknown tool %q", name)
+		}
+		argv, err := commandArgs(c, args)
+		if err != nil {
+			return "", err
+		}
+
+		cwd, err := os.Getwd()
+		if err != nil {
+			return "", err
+		}
+		defer os.Chdir(cwd)
+
+		var out bytes.Buffer
+		root.SetArgs(argv)
+		root.SetIn(strings.NewReader(""))
+		root.SetOut(&out)
+		root.SetErr(&out)
+		root.SilenceUsage = true
+		root.SilenceErrors = true
+		err = root.Execute()
+		return out.String(), err
+	}
+}
+
+func findTool(root *cobra.Command, name string) *cobra.Command {
+	var found *cobra.Command
+	var walk func(c *cobra.Command)
+	walk = func(c *cobra.Command) {
+		for _, sub := range c.Commands() {
+			if found == nil && ToolName(sub) == name && sub.Runnable() {
+				found = sub
+			}
+			walk(sub)
+		}
+	}
+	walk(root)
+	return found
+}
+
+// commandArgs turns tool arguments into a command line for c: the command
+// path, one --flag=value per flag argument, then the positional arguments.
+func commandArgs(c *cobra.Command, args map[string]any) ([]string, error) {
+	argv := strings.Fields(c.CommandPath())[1:]
+	var positional []string
+	for name, v := range args {
+		if name == argsProperty {
+			var err error
+			if positional, err = stringList(v); err != nil {
+				return nil, fmt.Errorf("%s: %w", argsProperty, err)
+			}
+			continue
+		}
+		f := c.Flags().Lookup(name)
+		if f == nil {
+			f = c.InheritedFlags().Lookup(name)
+		}
+		if f == nil || name == "help" || skippedFlag(f) || c.DisableFlagParsing {
+			return nil, fmt.Errorf("unknown argument %q", name)
+		}
+		switch val := v.(type) {
+		case nil:
+		case []any:
+			items, err := stringList(val)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			for _, item := range items {
+				argv = append(argv, "--"+name+"="+item)
+			}
+		default:
+			s, err := scalarString(val)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			argv = append(argv, "--"+name+"="+s)
+		}
+	}
+	// Flags are sorted so the command line is stable across calls.
+	sort.Strings(argv[len(strings.Fields(c.CommandPath()))-1:])
+
+	if len(positional) > 0 && !c.DisableFlagParsing {
+		argv = append(argv, "--")
+	}
+	return append(argv, positional...), nil
+}
+
+func skippedFlag(f *pflag.Flag) bool {
+	return len(f.Annotations[SkipAnnotation]) > 0
+}
+
+func stringList(v any) ([]string, error) {
+	switch val := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{val}, nil
+	case []any:
+		out := make([]string, 0, len(val))
+		for _, item := range val {
+			s, err := scalarString(item)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, s)
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("want a list of strings, got %T", v)
+}
+
+func scalarString(v any) (string, error) {
+	switch val := v.(type) {
+	case string:
+		return val, nil
+	case bool:
+		return strconv.FormatBool(val), nil
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), nil
+	}
+	return "", fmt.Errorf("unsupported value %v (%T)", v, v)
+}
@@ -0,0 +1,137 @@
+package mcp
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func testRoot() *cobra.Command {
+	var dir string
+	root := &cobra.Command{
+		Use: "tool",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if dir != "" {
+				return os.Chdir(dir)
+			}
+			return nil
+		},
+	}
+	root.PersistentFlags().StringVarP(&dir, "directory", "C", "", "Change to DIR")
+
+	var n int
+	var verbose bool
+	var tags []string
+	count := &cobra.Command{
+		Use:   "count [words...]",
+		Short: "Count words",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			wd, _ := os.Getwd()
+			fmt.Fprintf(cmd.OutOrStdout(), "n=%d verbose=%v tags=%v args=%v wd=%s\n", n, verbose, tags, args, wd)
+			if n < 0 {
+				return fmt.Errorf("negative")
+			}
+			return nil
+		},
+	}
+	count.Flags().IntVarP(&n, "n", "n", 3, "How many")
+	count.Flags().BoolVarP(&verbose, "verbose", "v", false, "Say more")
+	count.Flags().StringArrayVar(&tags, "tag", nil, "Tags")
+	count.Flags().Bool("force", false, "Do it for real")
+	count.Flags().SetAnnotation("force", SkipAnnotation, []string{"true"})
+
+	raw := &cobra.Command{
+		Use:                "raw [args...]",
+		Short:              "Raw args",
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fmt.Fprintf(cmd.OutOrStdout(), "%v\n", args)
+			return nil
+		},
+	}
+	group := &cobra.Command{Use: "grp", Short: "Group"}
+	group.AddCommand(&cobra.Command{Use: "sub", Short: "Sub", Run: func(cmd *cobra.Command, args []string) {}})
+	skipped := &cobra.Command{Use: "serve", Annotations: map[string]string{SkipAnnotation: "true"}, Run: func(*cobra.Command, []string) {}}
+
+	root.AddCommand(count, raw, group, skipped)
+	return root
+}
+
+func TestCommandTools(t *testing.T) {
+	tools := CommandTools(testRoot())
+	var names []string
+	byName := map[string]Tool{}
+	for _, tool := range tools {
+		names = append(names, tool.Name)
+		byName[tool.Name] = tool
+	}
+	if want := []string{"count", "grp-sub", "raw"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("tools = %v, want %v", names, want)
+	}
+
+	props := byName["count"].InputSchema["properties"].(map[string]any)
+	wantTypes := map[string]string{"n": "integer", "verbose": "boolean", "tag": "array", "directory": "string", "args": "array"}
+	for name, typ := range wantTypes {
+		p, ok := props[name].(map[string]any)
+		if !ok || p["type"] != typ {
+			t.Errorf("property %s = %v, want type %s", name, props[name], typ)
+		}
+	}
+	if props["n"].(map[string]any)["default"] != "3" {
+		t.Errorf("n default = %v", props["n"])
+	}
+	if _, ok := props["help"]; ok {
+		t.Error("help flag exposed")
+	}
+	if _, ok := props["force"]; ok {
+		t.Error("skipped flag exposed")
+	}
+	if rawProps := byName["raw"].InputSchema["properties"].(map[string]any); len(rawProps) != 1 {
+		t.Errorf("raw command should only take args, got %v", rawProps)
+	}
+}
+
+func TestCommandCaller(t *testing.T) {
+	call := CommandCaller(testRoot)
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+
+	out, err := call("count", map[string]any{
+		"n": float64(5), "verbose": true, "tag": []any{"a", "b"},
+		"args": []any{"-x", "y"}, "directory": dir,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "n=5 verbose=true tags=[a b] args=[-x y]") || !strings.Contains(out, "wd="+dir) {
+		t.Errorf("out = %q", out)
+	}
+	if now, _ := os.Getwd(); now != wd {
+		t.Errorf("working directory not restored: %s", now)
+	}
+
+	// Each call starts from fresh flag values.
+	if out, _ := call("count", nil); !strings.Contains(out, "n=3 verbose=false tags=[]") {
+		t.Errorf("defaults out = %q", out)
+	}
+
+	if out, _ := call("raw", map[string]any{"args": []any{"--stat", "main"}}); out != "[--stat main]\n" {
+		t.Errorf("raw out = %q", out)
+	}
+	if _, err := call("count", map[string]any{"n": float64(-1)}); err == nil || err.Error() != "negative" {
+		t.Errorf("err = %v", err)
+	}
+	if _, err := call("count", map[string]any{"bogus": "1"}); err == nil {
+		t.Error("expected error for unknown argument")
+	}
+	if _, err := call("count", map[string]any{"force": true}); err == nil {
+		t.Error("expected error for skipped flag")
+	}
+	if _, err := call("grp-sub", nil); err != nil {
+		t.Errorf("grp-sub: %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// ProtocolVersion is the MCP revision offered to clients that request one
+// this server does not know.
+const ProtocolVersion = "2025-06-18"
+
+// supportedVersions are the MCP revisions whose tools API this server
+// implements; a client requesting one of them gets it back.
+var supportedVersions = []string{"2024-11-05", "2025-03-26", "2025-06-18"}
+
+// JSON-RPC error codes.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// Tool describes one callable tool and the JSON schema of its arguments.
+type Tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	InputSchema map[string]any `json:"inputSchema"`
+}
+
+// CallFunc runs the named tool and returns its text output. A returned
+// error is reported to the client as a failed tool result, not a protocol
+// error.
+type CallFunc func(name string, args map[string]any) (string, error)
+
+// Server answers MCP requests over newline-delimited JSON-RPC.
+type Server struct {
+	Name    string
+	Version string
+	Tools   []Tool
+	Call    CallFunc
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type textContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type callResult struct {
+	Content []textContent `json:"content"`
+	IsError bool          `json:"isError,omitempty"`
+}
+
+// Serve reads one request per line from r and writes responses to w until
+// r is exhausted. Requests are handled one at a time, in order.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	enc := json.NewEncoder(w)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if resp := s.handle([]byte(line)); resp != nil {
+			if err := enc.Encode(resp); err != nil {
+				return err
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// handle answers one message; notifications get no response.
+func (s *Server) handle(msg []byte) *response {
+	var req request
+	if err := json.Unmarshal(msg, &req); err != nil {
+		return errorResponse(json.RawMessage("null"), codeParseError, fmt.Sprintf("parse error: %v", err))
+	}
+	if len(req.ID) == 0 {
+		return nil
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return errorResponse(req.ID, codeInvalidRequest, "invalid request")
+	}
+
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		json.Unmarshal(req.Params, &params)
+		version := ProtocolVersion
+		if slices.Contains(supportedVersions, params.ProtocolVersion) {
+			version = params.ProtocolVersion
+		}
+		return result(req.ID, map[string]any{
+			"protocolVersion": version,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]string{"name": s.Name, "version": s.Version},
+		})
+	case "ping":
+		return result(req.ID, struct{}{})
+	case "tools/list":
+		return result(req.ID, map[string]any{"tools": s.Tools})
+	case "tools/call":
+		var params struct {
+			Name      string         `json:"name"`
+			Arguments map[string]any `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return errorResponse(req.ID, codeInvalidParams, fmt.Sprintf("invalid params: %v", err))
+		}
+		if !s.hasTool(params.Name) {
+			return errorResponse(req.ID, codeInvalidParams, fmt.Sprintf("unknown tool %q", params.Name))
+		}
+		text, err := s.Call(params.Name, params.Arguments)
+		if err != nil {
+			if text != "" && !strings.HasSuffix(text, "\n") {
+				text += "\n"
+			}
+			return result(req.ID, callResult{Content: []textContent{{"text", text + "Error: " + err.Error()}}, IsError: true})
+		}
+		return result(req.ID, callResult{Content: []textContent{{"text", text}}})
+	}
+	return errorResponse(req.ID, codeMethodNotFound, fmt.Sprintf("method %q not found", req.Method))
+}
+
+func (s *Server) hasTool(name string) bool {
+	for _, t := range s.Tools {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func result(id json.RawMessage, v any) *response {
+	return &response{JSONRPC: "2.0", ID: id, Result: v}
+}
+
+func errorResponse(id json.RawMessage, code int, msg string) *response {
+	return &response{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: code, Message: msg}}
+}
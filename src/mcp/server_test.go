@@ -0,0 +1,132 @@
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func testServer() *Server {
+	return &Server{
+		Name:    "test",
+		Version: "1",
+		Tools:   []Tool{{Name: "echo", InputSchema: map[string]any{"type": "object"}}},
+		Call: func(name string, args map[string]any) (string, error) {
+			if args["fail"] == true {
+				return "partial", fmt.Errorf("boom")
+			}
+			return fmt.Sprintf("%s %v", name, args["msg"]), nil
+		},
+	}
+}
+
+// client drives a server over a pipe the way an MCP host does over stdio.
+type client struct {
+	t    *testing.T
+	in   *io.PipeWriter
+	out  *bufio.Scanner
+	done chan error
+	next int
+}
+
+func startClient(t *testing.T, s *Server) *client {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	c := &client{t: t, in: inW, out: bufio.NewScanner(outR), done: make(chan error, 1)}
+	go func() {
+		err := s.Serve(inR, outW)
+		outW.Close()
+		c.done <- err
+	}()
+	t.Cleanup(func() {
+		inW.Close()
+		if err := <-c.done; err != nil {
+			t.Errorf("Serve: %v", err)
+		}
+	})
+	return c
+}
+
+func (c *client) send(line string) {
+	c.t.Helper()
+	if _, err := io.WriteString(c.in, line+"\n"); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *client) call(method string, params any) map[string]any {
+	c.t.Helper()
+	c.next++
+	msg, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": c.next, "method": method, "params": params})
+	c.send(string(msg))
+	return c.read()
+}
+
+func (c *client) read() map[string]any {
+	c.t.Helper()
+	if !c.out.Scan() {
+		c.t.Fatalf("no response: %v", c.out.Err())
+	}
+	var resp map[string]any
+	if err := json.Unmarshal(c.out.Bytes(), &resp); err != nil {
+		c.t.Fatalf("bad response %q: %v", c.out.Text(), err)
+	}
+	return resp
+}
+
+func TestServe_Session(t *testing.T) {
+	c := startClient(t, testServer())
+
+	resp := c.call("initialize", map[string]any{"protocolVersion": "2024-11-05"})
+	result := resp["result"].(map[string]any)
+	if result["protocolVersion"] != "2024-11-05" {
+		t.Errorf("protocolVersion = %v", result["protocolVersion"])
+	}
+	if _, ok := result["capabilities"].(map[string]any)["tools"]; !ok {
+		t.Errorf("missing tools capability: %v", result)
+	}
+
+	// Notifications get no response; the next line answers the ping.
+	c.send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	if resp := c.call("ping", nil); resp["id"] != float64(2) || resp["result"] == nil {
+		t.Errorf("ping = %v", resp)
+	}
+
+	resp = c.call("tools/list", nil)
+	tools := resp["result"].(map[string]any)["tools"].([]any)
+	if len(tools) != 1 || tools[0].(map[string]any)["name"] != "echo" {
+		t.Errorf("tools = %v", tools)
+	}
+
+	resp = c.call("tools/call", map[string]any{"name": "echo", "arguments": map[string]any{"msg": "hi"}})
+	content := resp["result"].(map[string]any)["content"].([]any)[0].(map[string]any)
+	if content["type"] != "text" || content["text"] != "echo hi" {
+		t.Errorf("content = %v", content)
+	}
+
+	resp = c.call("tools/call", map[string]any{"name": "echo", "arguments": map[string]any{"fail": true}})
+	result = resp["result"].(map[string]any)
+	text := result["content"].([]any)[0].(map[string]any)["text"]
+	if result["isError"] != true || text != "partial\nError: boom" {
+		t.Errorf("failed call = %v", result)
+	}
+}
+
+func TestServe_Errors(t *testing.T) {
+	c := startClient(t, testServer())
+
+	c.send("{not json")
+	if resp := c.read(); resp["error"].(map[string]any)["code"] != float64(codeParseError) {
+		t.Errorf("parse error = %v", resp)
+	}
+	if resp := c.call("nope", nil); resp["error"].(map[string]any)["code"] != float64(codeMethodNotFound) {
+		t.Errorf("unknown method = %v", resp)
+	}
+	resp := c.call("tools/call", map[string]any{"name": "missing"})
+	if err := resp["error"].(map[string]any); err["code"] != float64(codeInvalidParams) || !strings.Contains(err["message"].(string), "missing") {
+		t.Errorf("unknown tool = %v", resp)
+	}
+}
@@ -0,0 +1,291 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"repotools/src/git"
+)
+
+// ChangedFn is a function whose lines overlap a hunk of the diff against the
+// merge base. Old is nil for added functions, New for removed ones; sizes are
+// span lines and code lines on each side.
+type ChangedFn struct {
+	File     string
+	Name     string
+	Old      *FnSpan
+	New      *FnSpan
+	OldLines int
+	NewLines int
+	OldCode  int
+	NewCode  int
+}
+
+// Status is "added", "removed" or "modified".
+func (c ChangedFn) Status() string {
+	switch {
+	case c.Old == nil:
+		return "added"
+	case c.New == nil:
+		return "removed"
+	}
+	return "modified"
+}
+
+// ChangedOptions selects the files and functions of a --changed report.
+type ChangedOptions struct {
+	// Base is the branch to diff against; the merge base with HEAD is used.
+	Base        string
+	Glob        string
+	ExcludePath string
+	Pattern     string
+	Include     string
+	Exclude     string
+}
+
+// ChangedFunctions diffs the working tree against the merge base of HEAD and
+// opts.Base and returns the functions touched by each hunk, matching the old
+// and new side of a function by name.
+func ChangedFunctions(paths []string, opts ChangedOptions) ([]ChangedFn, error) {
+	mb, err := git.MergeBase(opts.Base)
+	if err != nil {
+		return nil, fmt.Errorf("merge base with %s: %w", opts.Base, err)
+	}
+	files, err := git.DiffFrom(mb, append([]string{"--"}, paths...)...)
+	if err != nil {
+		return nil, err
+	}
+	excludePathRe, err := compileOptional(opts.ExcludePath)
+	if err != nil {
+		return nil, err
+	}
+	includeRe, err := compileOptional(opts.Include)
+	if err != nil {
+		return nil, err
+	}
+	excludeRe, err := compileOptional(opts.Exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []ChangedFn
+	for _, fd := range files {
+		path := fd.Path()
+		if opts.Glob != "" && !matchGlob(path, opts.Glob) {
+			continue
+		}
+		if excludePathRe != nil && excludePathRe.MatchString(path) {
+			continue
+		}
+		fnRe, err := fnPatternForFile(path, opts.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		if fnRe == nil {
+			continue
+		}
+
+		var oldLines, newLines []string
+		if fd.OldPath != "" {
+			content, err := git.Show(mb, fd.OldPath)
+			if err != nil {
+				return nil, err
+			}
+			oldLines = splitContent(content)
+		}
+		if fd.NewPath != "" {
+			if newLines, err = readLines(fd.NewPath); err != nil {
+				return nil, err
+			}
+		}
+		oldSpans := filterSpans(spansInLines(oldLines, fnRe, nil), includeRe, excludeRe)
+		newSpans := filterSpans(spansInLines(newLines, fnRe, nil), includeRe, excludeRe)
+		out = append(out, matchChanged(path, fd.Hunks, oldSpans, newSpans, oldLines, newLines)...)
+	}
+	return out, nil
+}
+
+// LabelHunks sets the Function of each hunk to the definition line of the
+// function its first change falls in, using the function spans of each
+// file as it is at rev. Paths are relative to the repository root, as git
+// diff prints them. Files without a function pattern keep git's context.
+func LabelHunks(files []git.FileDiff, rev string) error {
+	for i := range files {
+		f := &files[i]
+		if f.NewPath == "" || len(f.Hunks) == 0 {
+			continue
+		}
+		if fnRe, err := fnPatternForFile(f.NewPath, ""); err != nil || fnRe == nil {
+			continue
+		}
+		content, err := git.ShowFromRoot(rev, f.NewPath)
+		if err != nil {
+			return err
+		}
+		lines := splitContent(content)
+		spans, err := fnSpansInLines(f.NewPath, lines, "", "", "", "")
+		if err != nil {
+			return err
+		}
+		for j := range f.Hunks {
+			line := f.Hunks[j].FirstChange()
+			for _, s := range spans {
+				if s.Start <= line && line <= s.End && s.Start <= len(lines) {
+					f.Hunks[j].Function = strings.TrimSpace(lines[s.Start-1])
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// matchChanged pairs the touched old and new spans of one file by name.
+func matchChanged(path string, hunks []git.Hunk, oldSpans, newSpans []FnSpan, oldLines, newLines []string) []ChangedFn {
+	touched := func(s FnSpan, old bool) bool {
+		for _, h := range hunks {
+			start, end := h.NewRange()
+			if old {
+				start, end = h.OldRange()
+			}
+			// Pure insertions and deletions don't touch the other side.
+			if old && h.OldLines == 0 || !old && h.NewLines == 0 {
+				continue
+			}
+			if s.Start <= end && s.End >= start {
+				return true
+			}
+		}
+		return false
+	}
+	// Spans are keyed by name and occurrence, so two methods with the same
+	// name (String on two types) pair up in order instead of colliding.
+	keys := func(spans []FnSpan) ([]string, map[string]*FnSpan) {
+		ks := make([]string, len(spans))
+		m := make(map[string]*FnSpan)
+		count := make(map[string]int)
+		for i := range spans {
+			ks[i] = fmt.Sprintf("%s#%d", spans[i].Name, count[spans[i].Name])
+			count[spans[i].Name]++
+			m[ks[i]] = &spans[i]
+		}
+		return ks, m
+	}
+	oldKeys, oldByKey := keys(oldSpans)
+	newKeys, newByKey := keys(newSpans)
+
+	lang, _ := LanguageFor(path)
+	var out []ChangedFn
+	add := func(name string, o, n *FnSpan) {
+		c := ChangedFn{File: path, Name: name, Old: o, New: n}
+		if o != nil {
+			c.OldLines = o.End - o.Start + 1
+			c.OldCode = codeLines(oldLines[o.Start-1:o.End], lang)
+		}
+		if n != nil {
+			c.NewLines = n.End - n.Start + 1
+			c.NewCode = codeLines(newLines[n.Start-1:n.End], lang)
+		}
+		out = append(out, c)
+	}
+
+	for i := range newSpans {
+		n := &newSpans[i]
+		o := oldByKey[newKeys[i]]
+		if touched(*n, false) || o != nil && touched(*o, true) {
+			add(n.Name, o, n)
+		}
+	}
+	for i := range oldSpans {
+		o := &oldSpans[i]
+		if newByKey[oldKeys[i]] == nil && touched(*o, true) {
+			add(o.Name, o, nil)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return changedLine(out[i]) < changedLine(out[j]) })
+	return out
+}
+
+func changedLine(c ChangedFn) int {
+	if c.New != nil {
+		return c.New.Start
+	}
+	return c.Old.Start
+}
+
+// codeLines counts the non-blank, non-comment lines of a span.
+func codeLines(lines []string, lang Language) int {
+	cl := lineClassifier{lang: lang}
+	n := 0
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" && cl.classify(line) {
+			n++
+		}
+	}
+	return n
+}
+
+func splitContent(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+// RunChangedFnSpans prints the touched functions per file with their span
+// size before and after the change.
+func RunChangedFnSpans(w io.Writer, paths []string, opts ChangedOptions) error {
+	fns, err := ChangedFunctions(paths, opts)
+	if err != nil {
+		return err
+	}
+	if len(fns) == 0 {
+		return fmt.Errorf("No changed functions since %s.", opts.Base)
+	}
+	file := ""
+	for _, c := range fns {
+		if c.File != file {
+			if file != "" {
+				fmt.Fprintln(w)
+			}
+			file = c.File
+			fmt.Fprintf(w, "==> %s <==\n", file)
+		}
+		span := c.New
+		if span == nil {
+			span = c.Old
+		}
+		suffix := ""
+		if c.Status() != "modified" {
+			suffix = ", " + c.Status()
+		}
+		fmt.Fprintf(w, "  %d-%d %s (%d -> %d lines%s)\n", span.Start, span.End, c.Name, c.OldLines, c.NewLines, suffix)
+	}
+	return nil
+}
+
+// RunChangedLOC prints the code lines of each touched function before and
+// after the change, with a total.
+func RunChangedLOC(w io.Writer, paths []string, opts ChangedOptions) error {
+	fns, err := ChangedFunctions(paths, opts)
+	if err != nil {
+		return err
+	}
+	if len(fns) == 0 {
+		return fmt.Errorf("No changed functions since %s.", opts.Base)
+	}
+	var before, after int
+	fmt.Fprintf(w, "%7s %7s %7s  %s\n", "before", "after", "delta", "function")
+	for _, c := range fns {
+		span := c.New
+		if span == nil {
+			span = c.Old
+		}
+		fmt.Fprintf(w, "%7d %7d %+7d  %s:%d-%d %s\n", c.OldCode, c.NewCode, c.NewCode-c.OldCode, c.File, span.Start, span.End, c.Name)
+		before += c.OldCode
+		after += c.NewCode
+	}
+	fmt.Fprintf(w, "%7d %7d %+7d  total (%d functions)\n", before, after, after-before, len(fns))
+	return nil
+}
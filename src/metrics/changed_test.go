@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"repotools/src/git"
+)
+
+func setupChangedRepo(t *testing.T) func(args ...string) {
+	t.Helper()
+	run := initTestRepo(t)
+
+	base := "package p\n\nfunc keep() {\n}\n\nfunc edit() {\n\ta := 1\n}\n\nfunc drop() {\n}\n"
+	os.WriteFile("p.go", []byte(base), 0644)
+	run("git", "add", ".")
+	run("git", "commit", "-qm", "base")
+	run("git", "checkout", "-qb", "feature")
+
+	changed := "package p\n\nfunc keep() {\n}\n\nfunc edit() {\n\ta := 1\n\tb := 2\n\t_ = a + b\n}\n\nfunc fresh() {\n}\n"
+	os.WriteFile("p.go", []byte(changed), 0644)
+	return run
+}
+
+func TestChangedFunctions(t *testing.T) {
+	setupChangedRepo(t)
+
+	fns, err := ChangedFunctions(nil, ChangedOptions{Base: "main"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]ChangedFn)
+	for _, c := range fns {
+		got[c.Name] = c
+	}
+	if _, ok := got["keep"]; ok {
+		t.Error("untouched function keep reported")
+	}
+	if e := got["edit"]; e.Status() != "modified" || e.OldLines != 4 || e.NewLines != 6 || e.OldCode != 3 || e.NewCode != 5 {
+		t.Errorf("edit = %+v", e)
+	}
+	if f := got["fresh"]; f.Status() != "added" || f.NewLines != 2 {
+		t.Errorf("fresh = %+v", f)
+	}
+	if d := got["drop"]; d.Status() != "removed" || d.OldLines != 2 || d.NewLines != 0 {
+		t.Errorf("drop = %+v", d)
+	}
+}
+
+func TestRunChangedLOC(t *testing.T) {
+	setupChangedRepo(t)
+
+	var buf bytes.Buffer
+	if err := RunChangedLOC(&buf, nil, ChangedOptions{Base: "main", Glob: "*.go"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "total (3 functions)") {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestLabelHunks(t *testing.T) {
+	run := setupChangedRepo(t)
+	run("git", "commit", "-qam", "change")
+
+	patch, err := git.Diff("main..HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := git.ParseDiff(patch)
+	if err := LabelHunks(files, "HEAD"); err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || len(files[0].Hunks) == 0 {
+		t.Fatalf("files = %+v", files)
+	}
+	if got := files[0].Hunks[0].Function; got != "func edit() {" {
+		t.Errorf("first hunk function = %q, want %q", got, "func edit() {")
+	}
+}
+
+func TestMatchChanged_SameNamedMethods(t *testing.T) {
+	oldLines := strings.Split("package p\n\nfunc (A) String() string {\n\treturn \"a\"\n}\n\nfunc (B) String() string {\n\treturn \"b\"\n}", "\n")
+	newLines := strings.Split("package p\n\nfunc (A) String() string {\n\treturn \"a\"\n}\n\nfunc (B) String() string {\n\tb := \"b\"\n\treturn b\n}", "\n")
+	oldSpans, _ := fnSpansInLines("p.go", oldLines, "", "", "", "")
+	newSpans, _ := fnSpansInLines("p.go", newLines, "", "", "", "")
+	hunks := []git.Hunk{{OldStart: 8, OldLines: 1, NewStart: 8, NewLines: 2}}
+
+	fns := matchChanged("p.go", hunks, oldSpans, newSpans, oldLines, newLines)
+	if len(fns) != 1 {
+		t.Fatalf("got %d changed functions, want 1: %+v", len(fns), fns)
+	}
+	if c := fns[0]; c.Name != "String" || c.Old.Start != 7 || c.New.Start != 7 || c.OldLines != 3 || c.NewLines != 4 {
+		t.Errorf("String = %+v old %+v new %+v", c, c.Old, c.New)
+	}
+}
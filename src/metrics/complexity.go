@@ -0,0 +1,419 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// FnComplexity is the complexity of one function.
+type FnComplexity struct {
+	File       string
+	Name       string
+	Start      int
+	End        int
+	Cyclomatic int
+	Cognitive  int
+}
+
+func (c FnComplexity) Lines() int {
+	return c.End - c.Start + 1
+}
+
+// DefaultBranchPatterns match the branching constructs counted by the
+// heuristic (non-Go) complexity, one match per decision point.
+var DefaultBranchPatterns = map[string]string{
+	".rs": `\b(?:if|while|for|loop)\b|=>|&&|\|\||\?`,
+	".py": `\b(?:if|elif|for|while|except|and|or)\b`,
+	".js": `\b(?:if|for|while|case|catch)\b|&&|\|\||\?\?|\?[^.?]`,
+	".ts": `\b(?:if|for|while|case|catch)\b|&&|\|\||\?\?|\?[^.?:]`,
+}
+
+// nestingPattern matches branch keywords that open a nested block, used for
+// the heuristic cognitive score.
+var nestingPattern = regexp.MustCompile(`^\s*(?:\}\s*)?(?:else\s+)?(?:if|elif|for|while|loop|match|switch|try|except|catch)\b`)
+
+// ComplexityForFile computes per-function complexity: exactly via go/ast for
+// Go files, heuristically from branch keywords for other languages.
+func ComplexityForFile(path string, pattern string) ([]FnComplexity, error) {
+	if filepath.Ext(path) == ".go" && pattern == "" {
+		return GoComplexity(path)
+	}
+	return HeuristicComplexity(path, pattern)
+}
+
+// GoComplexity parses a Go file and scores each function and method.
+// Cyclomatic complexity is 1 plus each if, for, range, non-default case and
+// && / || operator. Cognitive complexity follows the SonarSource rules:
+// branches cost 1 plus their nesting depth, else branches and boolean
+// operator sequences cost 1.
+func GoComplexity(path string) ([]FnComplexity, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []FnComplexity
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		name := fn.Name.Name
+		if fn.Recv != nil && len(fn.Recv.List) > 0 {
+			name = recvTypeName(fn.Recv.List[0].Type) + "." + name
+		}
+		out = append(out, FnComplexity{
+			File:       path,
+			Name:       name,
+			Start:      fset.Position(fn.Pos()).Line,
+			End:        fset.Position(fn.End()).Line,
+			Cyclomatic: goCyclomatic(fn.Body),
+			Cognitive:  goCognitive(fn.Body, 0),
+		})
+	}
+	return out, nil
+}
+
+func recvTypeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return recvTypeName(t.X)
+	case *ast.IndexExpr:
+		return recvTypeName(t.X)
+	case *ast.IndexListExpr:
+		return recvTypeName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return "?"
+}
+
+func goCyclomatic(body ast.Node) int {
+	n := 1
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch x := node.(type) {
+		case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt:
+			n++
+		case *ast.CaseClause:
+			if x.List != nil {
+				n++
+			}
+		case *ast.CommClause:
+			if x.Comm != nil {
+				n++
+			}
+		case *ast.BinaryExpr:
+			if x.Op == token.LAND || x.Op == token.LOR {
+				n++
+			}
+		}
+		return true
+	})
+	return n
+}
+
+func goCognitive(node ast.Node, nesting int) int {
+	score := 0
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.IfStmt:
+			score += 1 + nesting + goCognitiveIf(x, nesting)
+			return false
+		case *ast.ForStmt:
+			score += 1 + nesting + goCognitiveParts(nesting, x.Init, x.Cond, x.Post) + goCognitive(x.Body, nesting+1)
+			return false
+		case *ast.RangeStmt:
+			score += 1 + nesting + goCognitiveParts(nesting, x.X) + goCognitive(x.Body, nesting+1)
+			return false
+		case *ast.SwitchStmt:
+			score += 1 + nesting + goCognitiveParts(nesting, x.Init, x.Tag) + goCognitive(x.Body, nesting+1)
+			return false
+		case *ast.TypeSwitchStmt:
+			score += 1 + nesting + goCognitive(x.Body, nesting+1)
+			return false
+		case *ast.SelectStmt:
+			score += 1 + nesting + goCognitive(x.Body, nesting+1)
+			return false
+		case *ast.FuncLit:
+			score += goCognitive(x.Body, nesting+1)
+			return false
+		case *ast.BranchStmt:
+			if x.Label != nil {
+				score++
+			}
+		case *ast.BinaryExpr:
+			if x.Op == token.LAND || x.Op == token.LOR {
+				score += boolSequences(x)
+				return false
+			}
+		}
+		return true
+	})
+	return score
+}
+
+// goCognitiveIf scores an if statement's condition, body and else chain
+// (excluding the if's own increment). else-if and else cost a flat 1.
+func goCognitiveIf(x *ast.IfStmt, nesting int) int {
+	score := goCognitiveParts(nesting, x.Init, x.Cond) + goCognitive(x.Body, nesting+1)
+	switch e := x.Else.(type) {
+	case *ast.IfStmt:
+		score += 1 + goCognitiveIf(e, nesting)
+	case *ast.BlockStmt:
+		score += 1 + goCognitive(e, nesting+1)
+	}
+	return score
+}
+
+func goCognitiveParts(nesting int, nodes ...ast.Node) int {
+	score := 0
+	for _, n := range nodes {
+		if n != nil {
+			score += goCognitive(n, nesting)
+		}
+	}
+	return score
+}
+
+// boolSequences counts runs of like boolean operators: a && b && c is 1,
+// a && b || c is 2.
+func boolSequences(expr ast.Expr) int {
+	var ops []token.Token
+	var flatten func(e ast.Expr)
+	flatten = func(e ast.Expr) {
+		if p, ok := e.(*ast.ParenExpr); ok {
+			e = p.X
+		}
+		b, ok := e.(*ast.BinaryExpr)
+		if !ok || (b.Op != token.LAND && b.Op != token.LOR) {
+			return
+		}
+		flatten(b.X)
+		ops = append(ops, b.Op)
+		flatten(b.Y)
+	}
+	flatten(expr)
+
+	n := 0
+	for i, op := range ops {
+		if i == 0 || ops[i-1] != op {
+			n++
+		}
+	}
+	return n
+}
+
+// HeuristicComplexity scores the functions found by ExtractFnSpans by
+// counting branch constructs per line with DefaultBranchPatterns. Nesting
+// for the cognitive score is estimated from indentation.
+func HeuristicComplexity(path string, pattern string) ([]FnComplexity, error) {
+	branchPat := DefaultBranchPatterns[filepath.Ext(path)]
+	if branchPat == "" {
+		branchPat = `\b(?:if|for|while|case|catch|except)\b|&&|\|\|`
+	}
+	branchRe, err := regexp.Compile(branchPat)
+	if err != nil {
+		return nil, err
+	}
+
+	spans, err := ExtractFnSpans(path, pattern, "", "", "")
+	if err != nil || len(spans) == 0 {
+		return nil, err
+	}
+
+	lines, err := readLines(path)
+	if err != nil {
+		return nil, err
+	}
+	lang, _ := LanguageFor(path)
+
+	var out []FnComplexity
+	for _, s := range spans {
+		fc := FnComplexity{File: path, Name: s.Name, Start: s.Start, End: s.End, Cyclomatic: 1}
+		base := indentWidth(lines[s.Start-1])
+		unit := indentUnit(lines[s.Start-1 : s.End])
+		cl := lineClassifier{lang: lang}
+		// The definition line counts too: defaults and return types can
+		// hold branches.
+		for _, line := range lines[s.Start-1 : s.End] {
+			if strings.TrimSpace(line) == "" || !cl.classify(line) {
+				continue
+			}
+			code := stripLineComment(line, lang)
+			n := len(branchRe.FindAllString(code, -1))
+			fc.Cyclomatic += n
+			if n == 0 {
+				continue
+			}
+			nesting := 0
+			if unit > 0 {
+				nesting = (indentWidth(line)-base)/unit - 1
+			}
+			if nesting < 0 {
+				nesting = 0
+			}
+			if nestingPattern.MatchString(code) {
+				fc.Cognitive += 1 + nesting + (n - 1)
+			} else {
+				fc.Cognitive += n
+			}
+		}
+		out = append(out, fc)
+	}
+	return out, nil
+}
+
+func readLines(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+func indentWidth(line string) int {
+	w := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			w++
+		case '\t':
+			w += 4
+		default:
+			return w
+		}
+	}
+	return w
+}
+
+// indentUnit is the smallest positive indentation step within lines.
+func indentUnit(lines []string) int {
+	unit := 0
+	prev := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		w := indentWidth(line)
+		if prev >= 0 && w > prev && (unit == 0 || w-prev < unit) {
+			unit = w - prev
+		}
+		prev = w
+	}
+	return unit
+}
+
+func stripLineComment(line string, lang Language) string {
+	for _, lc := range lang.LineComments {
+		if i := strings.Index(line, lc); i >= 0 {
+			line = line[:i]
+		}
+	}
+	return line
+}
+
+// ComplexityOptions selects files and shapes the complexity report.
+type ComplexityOptions struct {
+	Glob        string
+	ExcludePath string
+	Pattern     string
+	// Sort is "cyclomatic" (default), "cognitive", "lines" or "file".
+	Sort string
+	// Threshold hides functions whose sort metric is below it.
+	Threshold int
+	// Top limits output to the N worst functions; 0 shows all.
+	Top int
+}
+
+func complexityMetric(sortBy string) (func(FnComplexity) int, error) {
+	switch sortBy {
+	case "", "cyclomatic":
+		return func(c FnComplexity) int { return c.Cyclomatic }, nil
+	case "cognitive":
+		return func(c FnComplexity) int { return c.Cognitive }, nil
+	case "lines":
+		return func(c FnComplexity) int { return c.Lines() }, nil
+	case "file":
+		return func(c FnComplexity) int { return c.Cyclomatic }, nil
+	}
+	return nil, fmt.Errorf("unknown sort %q (want cyclomatic, cognitive, lines or file)", sortBy)
+}
+
+func RunComplexity(w io.Writer, paths []string, opts ComplexityOptions) error {
+	metric, err := complexityMetric(opts.Sort)
+	if err != nil {
+		return err
+	}
+	files, err := ResolveFiles(paths, opts.Glob, opts.ExcludePath)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("No files found.")
+	}
+
+	type fileResult struct {
+		fns []FnComplexity
+		err error
+	}
+	perFile, err := mapFiles(files, func(f string) (fileResult, error) {
+		if fnRe, _ := fnPatternForFile(f, opts.Pattern); fnRe == nil {
+			return fileResult{}, nil
+		}
+		fns, err := ComplexityForFile(f, opts.Pattern)
+		return fileResult{fns, err}, nil
+	})
+	if err != nil {
+		return err
+	}
+	var all []FnComplexity
+	for i, r := range perFile {
+		// One file that does not parse should not hide the rest.
+		if r.err != nil {
+			fmt.Fprintf(os.Stderr, "Skipping %s: %v\n", files[i], r.err)
+			continue
+		}
+		all = append(all, r.fns...)
+	}
+	if len(all) == 0 {
+		return fmt.Errorf("No functions found.")
+	}
+	total := len(all)
+
+	var kept []FnComplexity
+	for _, c := range all {
+		if metric(c) >= opts.Threshold {
+			kept = append(kept, c)
+		}
+	}
+	if opts.Sort != "file" {
+		sort.SliceStable(kept, func(i, j int) bool { return metric(kept[i]) > metric(kept[j]) })
+	}
+	if opts.Top > 0 && len(kept) > opts.Top {
+		kept = kept[:opts.Top]
+	}
+
+	fmt.Fprintf(w, "%5s %5s %6s  %s\n", "cyc", "cog", "lines", "function")
+	for _, c := range kept {
+		fmt.Fprintf(w, "%5d %5d %6d  %s:%d-%d %s\n", c.Cyclomatic, c.Cognitive, c.Lines(), c.File, c.Start, c.End, c.Name)
+	}
+	fmt.Fprintf(w, "(%d of %d functions shown)\n", len(kept), total)
+	return nil
+}
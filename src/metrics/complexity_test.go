@@ -0,0 +1,161 @@
+package metrics
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const complexGo = `package a
+
+func f(a, b int) int {
+	if a > 0 && b > 0 {
+		for i := 0; i < a; i++ {
+			if i == b {
+				return i
+			}
+		}
+	} else if a < 0 {
+		return -1
+	} else {
+		return 0
+	}
+	switch b {
+	case 1, 2:
+		return 1
+	default:
+	}
+	return 2
+}
+
+func (s *S) simple() {}
+`
+
+const complexPy = `def g(x):
+    if x and y:
+        for i in x:
+            pass
+    return x
+
+def h():
+    return 1
+`
+
+func writeFixture(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestGoComplexity(t *testing.T) {
+	path := writeFixture(t, "a.go", complexGo)
+	fns, err := GoComplexity(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fns) != 2 {
+		t.Fatalf("got %d functions, want 2", len(fns))
+	}
+	f := fns[0]
+	if f.Name != "f" || f.Start != 3 || f.End != 21 {
+		t.Errorf("f span = %s %d-%d, want f 3-21", f.Name, f.Start, f.End)
+	}
+	if f.Cyclomatic != 7 {
+		t.Errorf("cyclomatic = %d, want 7", f.Cyclomatic)
+	}
+	if f.Cognitive != 10 {
+		t.Errorf("cognitive = %d, want 10", f.Cognitive)
+	}
+	if s := fns[1]; s.Name != "S.simple" || s.Cyclomatic != 1 || s.Cognitive != 0 {
+		t.Errorf("method = %+v, want S.simple 1/0", s)
+	}
+}
+
+func TestBoolSequences(t *testing.T) {
+	path := writeFixture(t, "b.go", "package b\n\nfunc f(a, b, c bool) bool {\n\treturn a && b && c || a\n}\n")
+	fns, err := GoComplexity(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fns[0].Cyclomatic != 4 || fns[0].Cognitive != 2 {
+		t.Errorf("got cyc %d cog %d, want 4 / 2", fns[0].Cyclomatic, fns[0].Cognitive)
+	}
+}
+
+func TestHeuristicComplexity_Python(t *testing.T) {
+	path := writeFixture(t, "a.py", complexPy)
+	fns, err := ComplexityForFile(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fns) != 2 {
+		t.Fatalf("got %d functions, want 2", len(fns))
+	}
+	if fns[0].Cyclomatic != 4 || fns[0].Cognitive != 4 {
+		t.Errorf("g = cyc %d cog %d, want 4 / 4", fns[0].Cyclomatic, fns[0].Cognitive)
+	}
+	if fns[1].Cyclomatic != 1 {
+		t.Errorf("h = cyc %d, want 1", fns[1].Cyclomatic)
+	}
+}
+
+func TestHeuristicComplexity_DefinitionLine(t *testing.T) {
+	path := writeFixture(t, "d.py", "def f(x=a if b else c):\n    return x\n")
+	fns, err := ComplexityForFile(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fns) != 1 || fns[0].Cyclomatic != 2 {
+		t.Errorf("got %+v, want f with cyclomatic 2", fns)
+	}
+}
+
+func TestRunComplexity_TopAndThreshold(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.go"), []byte(complexGo), 0644)
+	os.WriteFile(filepath.Join(dir, "a.py"), []byte(complexPy), 0644)
+
+	var buf bytes.Buffer
+	if err := RunComplexity(&buf, []string{dir}, ComplexityOptions{Top: 2}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 4 || !strings.Contains(lines[1], " f") || !strings.Contains(lines[2], " g") {
+		t.Errorf("want f then g as top 2:\n%s", out)
+	}
+	if !strings.Contains(out, "(2 of 4 functions shown)") {
+		t.Errorf("missing summary in:\n%s", out)
+	}
+
+	buf.Reset()
+	if err := RunComplexity(&buf, []string{dir}, ComplexityOptions{Sort: "cognitive", Threshold: 5}); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, "(1 of 4 functions shown)") {
+		t.Errorf("threshold should keep only f:\n%s", out)
+	}
+
+	if err := RunComplexity(&buf, []string{dir}, ComplexityOptions{Sort: "bogus"}); err == nil {
+		t.Error("expected error for unknown sort")
+	}
+}
+
+func TestRunComplexity_SkipsUnparseable(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.go"), []byte(complexGo), 0644)
+	os.WriteFile(filepath.Join(dir, "broken.go"), []byte("package a\n\nfunc {\n"), 0644)
+
+	var buf bytes.Buffer
+	if err := RunComplexity(&buf, []string{dir}, ComplexityOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, " f") {
+		t.Errorf("a.go missing after broken.go failed to parse:\n%s", out)
+	}
+}
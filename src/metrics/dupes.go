@@ -0,0 +1,256 @@
+package metrics
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DupesOptions selects files and tunes duplicate detection.
+type DupesOptions struct {
+	Glob    string
+	Exclude string
+	// MinLines is the smallest duplicate reported, in normalized lines.
+	MinLines int
+	// Identifiers replaces identifiers with a placeholder so renamed copies
+	// still match.
+	Identifiers bool
+	Top         int
+}
+
+// DupeLocation is one copy of a duplicated block, in original line numbers.
+type DupeLocation struct {
+	File  string
+	Start int
+	End   int
+}
+
+// DupeGroup is a block of Lines normalized lines found at every location.
+type DupeGroup struct {
+	Lines     int
+	Locations []DupeLocation
+}
+
+// Impact is the number of lines that could be removed by deduplicating.
+func (g DupeGroup) Impact() int {
+	return g.Lines * (len(g.Locations) - 1)
+}
+
+var (
+	identRe       = regexp.MustCompile(`[A-Za-z_]\w*`)
+	punctuationRe = regexp.MustCompile(`^[\s{}()\[\];,]*$`)
+)
+
+// dupeKeywords survive identifier stripping so that control flow still has
+// to match.
+var dupeKeywords = map[string]bool{
+	"if": true, "else": true, "elif": true, "for": true, "while": true, "loop": true,
+	"switch": true, "case": true, "match": true, "default": true, "break": true,
+	"continue": true, "return": true, "yield": true, "try": true, "catch": true,
+	"except": true, "finally": true, "func": true, "def": true, "fn": true,
+	"function": true, "class": true, "struct": true, "type": true, "var": true,
+	"let": true, "const": true, "go": true, "defer": true, "range": true,
+	"async": true, "await": true, "new": true, "in": true, "and": true,
+	"or": true, "not": true, "true": true, "false": true, "nil": true,
+	"null": true, "None": true,
+}
+
+// normLine is a normalized source line and where it came from.
+type normLine struct {
+	text string
+	line int
+	hash uint64
+}
+
+// normalizeLines drops blank, comment-only and punctuation-only lines and
+// trims the rest, optionally replacing identifiers with "_".
+func normalizeLines(path string, identifiers bool) ([]normLine, error) {
+	lines, err := readLines(path)
+	if err != nil {
+		return nil, err
+	}
+	lang, _ := LanguageFor(path)
+	cl := lineClassifier{lang: lang}
+
+	var out []normLine
+	for i, line := range lines {
+		text := strings.TrimSpace(line)
+		if text == "" || !cl.classify(line) || punctuationRe.MatchString(text) {
+			continue
+		}
+		if identifiers {
+			text = identRe.ReplaceAllStringFunc(text, func(id string) string {
+				if dupeKeywords[id] {
+					return id
+				}
+				return "_"
+			})
+		}
+		h := fnv.New64a()
+		h.Write([]byte(text))
+		out = append(out, normLine{text: text, line: i + 1, hash: h.Sum64()})
+	}
+	return out, nil
+}
+
+type dupeOcc struct {
+	file int
+	pos  int
+}
+
+// FindDupes finds blocks of at least minLines normalized lines repeated
+// across or within files. Windows of minLines lines are matched with a
+// rolling hash, then consecutive matching windows are merged into maximal
+// blocks.
+func FindDupes(files []string, minLines int, identifiers bool) ([]DupeGroup, error) {
+	if minLines < 2 {
+		minLines = 2
+	}
+	norm, err := mapFiles(files, func(f string) ([]normLine, error) {
+		return normalizeLines(f, identifiers)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	const base = 1099511628211
+	var pow uint64 = 1
+	for i := 1; i < minLines; i++ {
+		pow *= base
+	}
+	windows := make(map[uint64][]dupeOcc)
+	for fi, lines := range norm {
+		var h uint64
+		for i, l := range lines {
+			if i >= minLines {
+				h -= lines[i-minLines].hash * pow
+			}
+			h = h*base + l.hash
+			if i >= minLines-1 {
+				windows[h] = append(windows[h], dupeOcc{fi, i - minLines + 1})
+			}
+		}
+	}
+
+	sameWindow := func(a, b dupeOcc) bool {
+		for k := 0; k < minLines; k++ {
+			if norm[a.file][a.pos+k].text != norm[b.file][b.pos+k].text {
+				return false
+			}
+		}
+		return true
+	}
+
+	// Split each hash bucket into verified classes of non-overlapping copies.
+	var classes [][]dupeOcc
+	for _, occs := range windows {
+		if len(occs) < 2 {
+			continue
+		}
+		for len(occs) > 0 {
+			first := occs[0]
+			class := []dupeOcc{first}
+			var rest []dupeOcc
+			for _, o := range occs[1:] {
+				if !sameWindow(first, o) {
+					rest = append(rest, o)
+					continue
+				}
+				last := class[len(class)-1]
+				if o.file != last.file || o.pos >= last.pos+minLines {
+					class = append(class, o)
+				}
+			}
+			if len(class) > 1 {
+				classes = append(classes, class)
+			}
+			occs = rest
+		}
+	}
+	sort.Slice(classes, func(i, j int) bool {
+		a, b := classes[i][0], classes[j][0]
+		if a.file != b.file {
+			return a.file < b.file
+		}
+		return a.pos < b.pos
+	})
+
+	// Merge a class into the block whose copies each end one window earlier.
+	type block struct {
+		start  []dupeOcc
+		length int
+	}
+	signature := func(occs []dupeOcc, shift int) string {
+		var sb strings.Builder
+		for _, o := range occs {
+			fmt.Fprintf(&sb, "%d:%d,", o.file, o.pos+shift)
+		}
+		return sb.String()
+	}
+	byEnd := make(map[string]*block)
+	var blocks []*block
+	for _, class := range classes {
+		if b := byEnd[signature(class, -1)]; b != nil {
+			delete(byEnd, signature(class, -1))
+			b.length++
+			byEnd[signature(class, 0)] = b
+			continue
+		}
+		b := &block{start: class, length: minLines}
+		blocks = append(blocks, b)
+		byEnd[signature(class, 0)] = b
+	}
+
+	groups := make([]DupeGroup, 0, len(blocks))
+	for _, b := range blocks {
+		g := DupeGroup{Lines: b.length}
+		for _, o := range b.start {
+			lines := norm[o.file]
+			g.Locations = append(g.Locations, DupeLocation{
+				File:  files[o.file],
+				Start: lines[o.pos].line,
+				End:   lines[o.pos+b.length-1].line,
+			})
+		}
+		groups = append(groups, g)
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Impact() > groups[j].Impact() })
+	return groups, nil
+}
+
+func RunDupes(w io.Writer, paths []string, opts DupesOptions) error {
+	files, err := ResolveFiles(paths, opts.Glob, opts.Exclude)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("No files found.")
+	}
+	groups, err := FindDupes(files, opts.MinLines, opts.Identifiers)
+	if err != nil {
+		return err
+	}
+	if len(groups) == 0 {
+		return fmt.Errorf("No duplicates found.")
+	}
+
+	total, dupLines := len(groups), 0
+	for _, g := range groups {
+		dupLines += g.Impact()
+	}
+	if opts.Top > 0 && len(groups) > opts.Top {
+		groups = groups[:opts.Top]
+	}
+	for _, g := range groups {
+		fmt.Fprintf(w, "%d lines x%d (impact %d)\n", g.Lines, len(g.Locations), g.Impact())
+		for _, loc := range g.Locations {
+			fmt.Fprintf(w, "  %s:%d-%d\n", loc.File, loc.Start, loc.End)
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "%d duplicate groups (%d shown), %d duplicated lines\n", total, len(groups), dupLines)
+	return nil
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const dupeBlock = `	total := 0
+	for _, item := range items {
+		if item.Price > 0 {
+			total += item.Price * item.Qty
+		}
+	}
+	fmt.Println(total)
+`
+
+func TestFindDupes(t *testing.T) {
+	a := writeFixture(t, "a.go", "package p\n\nfunc a() {\n"+dupeBlock+"}\n")
+	b := writeFixture(t, "b.go", "package p\n\n// copy\nfunc b() {\n\n"+dupeBlock+"}\n")
+	renamed := strings.NewReplacer("total", "sum", "item", "it").Replace(dupeBlock)
+	c := writeFixture(t, "c.go", "package p\n\nfunc c() {\n"+renamed+"}\n")
+
+	groups, err := FindDupes([]string{a, b, c}, 4, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 1 {
+		t.Fatalf("got %d groups, want 1: %+v", len(groups), groups)
+	}
+	g := groups[0]
+	if g.Lines != 5 || len(g.Locations) != 2 {
+		t.Fatalf("group = %+v", g)
+	}
+	if g.Locations[0] != (DupeLocation{a, 4, 10}) || g.Locations[1] != (DupeLocation{b, 6, 12}) {
+		t.Errorf("locations = %+v", g.Locations)
+	}
+
+	groups, err = FindDupes([]string{a, b, c}, 4, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) == 0 || len(groups[0].Locations) != 3 || groups[0].Impact() != 2*groups[0].Lines {
+		t.Errorf("with identifiers stripped: %+v", groups)
+	}
+}
+
+func TestRunDupes_NoDuplicates(t *testing.T) {
+	a := writeFixture(t, "a.go", "package p\n\nfunc a() {\n"+dupeBlock+"}\n")
+
+	var buf bytes.Buffer
+	if err := RunDupes(&buf, []string{filepath.Dir(a)}, DupesOptions{MinLines: 4}); err == nil {
+		t.Errorf("expected no duplicates, got:\n%s", buf.String())
+	}
+}
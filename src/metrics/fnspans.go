@@ -1,12 +1,13 @@
 package metrics
 
 import (
-	"bufio"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
+	"strings"
 )
 
 type FnSpan struct {
@@ -36,6 +37,61 @@ func fnPatternForFile(path string, explicit string) (*regexp.Regexp, error) {
 }
 
 func ExtractFnSpans(path string, pattern string, after string, include string, exclude string) ([]FnSpan, error) {
+	lines, err := readLines(path)
+	if err != nil {
+		return nil, err
+	}
+	return fnSpansInLines(path, lines, pattern, after, include, exclude)
+}
+
+// goReceiverRe captures the receiver type of a Go method definition.
+var goReceiverRe = regexp.MustCompile(`^func\s+\(\s*(?:\w+\s+)?\*?(\w+)`)
+
+// FindFnSpan returns the span of the function named name in path. A Go
+// method can be named Type.Method; a name matching several functions is an
+// error listing their lines.
+func FindFnSpan(path string, name string) (FnSpan, error) {
+	lines, err := readLines(path)
+	if err != nil {
+		return FnSpan{}, err
+	}
+	spans, err := fnSpansInLines(path, lines, "", "", "", "")
+	if err != nil {
+		return FnSpan{}, err
+	}
+	recv, fn, qualified := strings.Cut(name, ".")
+	if !qualified {
+		fn = name
+	}
+	var found []FnSpan
+	for _, s := range spans {
+		if s.Name != fn {
+			continue
+		}
+		if qualified {
+			m := goReceiverRe.FindStringSubmatch(lines[s.Start-1])
+			if m == nil || m[1] != recv {
+				continue
+			}
+		}
+		found = append(found, s)
+	}
+	switch len(found) {
+	case 0:
+		return FnSpan{}, fmt.Errorf("no function %q in %s", name, path)
+	case 1:
+		return found[0], nil
+	}
+	starts := make([]string, len(found))
+	for i, s := range found {
+		starts[i] = strconv.Itoa(s.Start)
+	}
+	return FnSpan{}, fmt.Errorf("function %q is ambiguous in %s (lines %s)", name, path, strings.Join(starts, ", "))
+}
+
+// fnSpansInLines is ExtractFnSpans over lines already read, such as a file
+// at another revision.
+func fnSpansInLines(path string, lines []string, pattern string, after string, include string, exclude string) ([]FnSpan, error) {
 	fnRe, err := fnPatternForFile(path, pattern)
 	if err != nil {
 		return nil, err
@@ -64,21 +120,12 @@ func ExtractFnSpans(path string, pattern string, after string, include string, e
 		}
 	}
 
-	f, err := os.Open(path)
-	if err != nil {
-		return nil, err
-	}
-	defer f.Close()
-
-	var lines []string
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
-	}
-	if err := scanner.Err(); err != nil {
-		return nil, err
-	}
+	return filterSpans(spansInLines(lines, fnRe, afterRe), includeRe, excludeRe), nil
+}
 
+// spansInLines finds function definitions in lines; each span runs to the
+// line before the next definition, or to the end of the file.
+func spansInLines(lines []string, fnRe, afterRe *regexp.Regexp) []FnSpan {
 	scanning := afterRe == nil
 	type raw struct {
 		line int
@@ -101,7 +148,7 @@ func ExtractFnSpans(path string, pattern string, after string, include string, e
 	}
 
 	if len(raws) == 0 {
-		return nil, nil
+		return nil
 	}
 
 	var spans []FnSpan
@@ -112,7 +159,10 @@ func ExtractFnSpans(path string, pattern string, after string, include string, e
 		}
 		spans = append(spans, FnSpan{Start: r.line, End: end, Name: r.name})
 	}
+	return spans
+}
 
+func filterSpans(spans []FnSpan, includeRe, excludeRe *regexp.Regexp) []FnSpan {
 	if includeRe != nil {
 		var filtered []FnSpan
 		for _, s := range spans {
@@ -131,8 +181,7 @@ func ExtractFnSpans(path string, pattern string, after string, include string, e
 		}
 		spans = filtered
 	}
-
-	return spans, nil
+	return spans
 }
 
 func RunFnSpans(w io.Writer, paths []string, globPattern, excludePath, pattern, after, include, exclude string) error {
@@ -146,7 +195,14 @@ func RunFnSpans(w io.Writer, paths []string, globPattern, excludePath, pattern,
 
 	multi := len(files) > 1
 
-	for _, f := range files {
+	allSpans, err := mapFiles(files, func(f string) ([]FnSpan, error) {
+		return ExtractFnSpans(f, pattern, after, include, exclude)
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, f := range files {
 		fnRe, _ := fnPatternForFile(f, pattern)
 		if fnRe == nil {
 			if !multi {
@@ -156,10 +212,7 @@ func RunFnSpans(w io.Writer, paths []string, globPattern, excludePath, pattern,
 			continue
 		}
 
-		spans, err := ExtractFnSpans(f, pattern, after, include, exclude)
-		if err != nil {
-			return err
-		}
+		spans := allSpans[i]
 		if len(spans) == 0 {
 			if !multi {
 				return fmt.Errorf("No functions found.")
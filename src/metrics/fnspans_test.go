@@ -2,6 +2,8 @@ package metrics
 
 import (
 	"bytes"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -104,3 +106,40 @@ func TestRunFnSpans_MultiFile(t *testing.T) {
 		t.Errorf("multi-file output missing headers:\n%s", out)
 	}
 }
+
+func TestFindFnSpan(t *testing.T) {
+	span, err := FindFnSpan("../../testdata/fixtures/sample.go", "helper")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spans, _ := ExtractFnSpans("../../testdata/fixtures/sample.go", "", "", "", "")
+	if span != spans[1] {
+		t.Errorf("FindFnSpan = %+v, want %+v", span, spans[1])
+	}
+	if _, err := FindFnSpan("../../testdata/fixtures/sample.go", "missing"); err == nil {
+		t.Error("FindFnSpan(missing) succeeded")
+	}
+}
+
+func TestFindFnSpan_Ambiguous(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "f.go")
+	src := "package p\n\nfunc (a A) String() string { return \"a\" }\n\nfunc (b *B) String() string { return \"b\" }\n"
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := FindFnSpan(path, "String")
+	if err == nil || !strings.Contains(err.Error(), "lines 3, 5") {
+		t.Errorf("FindFnSpan(String) error = %v, want ambiguity listing lines 3, 5", err)
+	}
+	span, err := FindFnSpan(path, "B.String")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if span.Start != 5 {
+		t.Errorf("FindFnSpan(B.String) starts at %d, want 5", span.Start)
+	}
+	if _, err := FindFnSpan(path, "C.String"); err == nil {
+		t.Error("FindFnSpan(C.String) succeeded")
+	}
+}
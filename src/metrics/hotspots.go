@@ -0,0 +1,241 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+
+	"repotools/src/git"
+)
+
+// Hotspot ranks one file (or function) by how often it changes and how big
+// it is: Score is Commits times Size.
+type Hotspot struct {
+	Path    string
+	Name    string
+	Start   int
+	End     int
+	Commits int
+	Changed int
+	Size    int
+	Score   int
+}
+
+// HotspotOptions selects the history window and how files are sized.
+type HotspotOptions struct {
+	// Since is the history window: "90d", "12w", "6m", "1y" or a date.
+	Since   string
+	Glob    string
+	Exclude string
+	// Complexity sizes files (or functions) by total cyclomatic complexity
+	// instead of lines of code.
+	Complexity bool
+	// Functions attributes churn to functions instead of files.
+	Functions bool
+	Top       int
+}
+
+func (o HotspotOptions) logArgs(paths []string) []string {
+	var args []string
+	if o.Since != "" {
+		args = append(args, git.SinceArg(o.Since))
+	}
+	return append(append(args, "--"), paths...)
+}
+
+func (o HotspotOptions) keep(path string, excludeRe *regexp.Regexp) bool {
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return false
+	}
+	if o.Glob != "" && !matchGlob(path, o.Glob) {
+		return false
+	}
+	return excludeRe == nil || !excludeRe.MatchString(path)
+}
+
+// FileHotspots ranks the files changed in the window that still exist.
+func FileHotspots(paths []string, opts HotspotOptions) ([]Hotspot, error) {
+	excludeRe, err := compileOptional(opts.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	churn, err := git.Churn(opts.logArgs(paths)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var spots []Hotspot
+	for path, c := range churn {
+		if !opts.keep(path, excludeRe) {
+			continue
+		}
+		size, err := fileSize(path, opts.Complexity)
+		if err != nil {
+			return nil, err
+		}
+		spots = append(spots, Hotspot{
+			Path:    path,
+			Commits: c.Commits,
+			Changed: c.Added + c.Deleted,
+			Size:    size,
+			Score:   c.Commits * size,
+		})
+	}
+	sortHotspots(spots)
+	return spots, nil
+}
+
+func fileSize(path string, complexity bool) (int, error) {
+	if !complexity {
+		c, err := CountLinesAutoDetect(path, "")
+		return c.Code + c.Test, err
+	}
+	if fnRe, _ := fnPatternForFile(path, ""); fnRe == nil {
+		return 0, nil
+	}
+	fns, err := ComplexityForFile(path, "")
+	if err != nil {
+		// Unparseable files still rank by churn alone.
+		return 0, nil
+	}
+	total := 0
+	for _, f := range fns {
+		total += f.Cyclomatic
+	}
+	return total, nil
+}
+
+// FunctionHotspots attributes each commit's changed hunks to the functions
+// they overlap. Hunks are located in the file as it was at that commit but
+// matched against today's function spans, so churn on functions that moved
+// a lot is approximate.
+func FunctionHotspots(paths []string, opts HotspotOptions) ([]Hotspot, error) {
+	excludeRe, err := compileOptional(opts.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	commits, err := git.LogDiffs(opts.logArgs(paths)...)
+	if err != nil {
+		return nil, err
+	}
+
+	type fnKey struct {
+		path string
+		name string
+		line int
+	}
+	spans := make(map[string][]FnSpan)
+	complexity := make(map[string]map[int]int)
+	spots := make(map[fnKey]*Hotspot)
+
+	for _, c := range commits {
+		for _, fd := range c.Files {
+			path := fd.NewPath
+			if path == "" || !opts.keep(path, excludeRe) {
+				continue
+			}
+			fileSpans, seen := spans[path]
+			if !seen {
+				fileSpans, _ = ExtractFnSpans(path, "", "", "", "")
+				spans[path] = fileSpans
+			}
+
+			touched := make(map[fnKey]bool)
+			for _, h := range fd.Hunks {
+				start, end := h.NewRange()
+				for _, s := range fileSpans {
+					if s.End < start || s.Start > end {
+						continue
+					}
+					key := fnKey{path, s.Name, s.Start}
+					hs := spots[key]
+					if hs == nil {
+						hs = &Hotspot{Path: path, Name: s.Name, Start: s.Start, End: s.End, Size: s.End - s.Start + 1}
+						spots[key] = hs
+					}
+					hs.Changed += h.OldLines + h.NewLines
+					touched[key] = true
+				}
+			}
+			for key := range touched {
+				spots[key].Commits++
+			}
+		}
+	}
+
+	var out []Hotspot
+	for _, hs := range spots {
+		if opts.Complexity {
+			if complexity[hs.Path] == nil {
+				complexity[hs.Path] = make(map[int]int)
+				fns, _ := ComplexityForFile(hs.Path, "")
+				for _, f := range fns {
+					complexity[hs.Path][f.Start] = f.Cyclomatic
+				}
+			}
+			hs.Size = complexity[hs.Path][hs.Start]
+		}
+		hs.Score = hs.Commits * hs.Size
+		out = append(out, *hs)
+	}
+	sortHotspots(out)
+	return out, nil
+}
+
+func sortHotspots(spots []Hotspot) {
+	sort.Slice(spots, func(i, j int) bool {
+		if spots[i].Score != spots[j].Score {
+			return spots[i].Score > spots[j].Score
+		}
+		if spots[i].Path != spots[j].Path {
+			return spots[i].Path < spots[j].Path
+		}
+		return spots[i].Start < spots[j].Start
+	})
+}
+
+func compileOptional(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	return regexp.Compile(pattern)
+}
+
+func RunHotspots(w io.Writer, paths []string, opts HotspotOptions) error {
+	var spots []Hotspot
+	var err error
+	if opts.Functions {
+		spots, err = FunctionHotspots(paths, opts)
+	} else {
+		spots, err = FileHotspots(paths, opts)
+	}
+	if err != nil {
+		return err
+	}
+	if len(spots) == 0 {
+		return fmt.Errorf("No changes found.")
+	}
+	total := len(spots)
+	if opts.Top > 0 && len(spots) > opts.Top {
+		spots = spots[:opts.Top]
+	}
+
+	sizeLabel := "loc"
+	if opts.Complexity {
+		sizeLabel = "cyc"
+	}
+	fmt.Fprintf(w, "%7s %7s %7s %6s  %s\n", "score", "commits", "changed", sizeLabel, "location")
+	for _, s := range spots {
+		loc := s.Path
+		if opts.Functions {
+			loc = fmt.Sprintf("%s:%d-%d %s", s.Path, s.Start, s.End, s.Name)
+		}
+		fmt.Fprintf(w, "%7d %7d %7d %6d  %s\n", s.Score, s.Commits, s.Changed, s.Size, loc)
+	}
+	if len(spots) < total {
+		fmt.Fprintf(w, "(%d of %d shown)\n", len(spots), total)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// initTestRepo chdirs into a new git repository on branch main and returns
+// a helper that runs a command there, failing the test on error.
+func initTestRepo(t *testing.T) func(args ...string) {
+	t.Helper()
+	dir := t.TempDir()
+	oldDir, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(oldDir) })
+
+	run := func(args ...string) {
+		t.Helper()
+		if out, err := exec.Command(args[0], args[1:]...).CombinedOutput(); err != nil {
+			t.Fatalf("%v: %s: %v", args, out, err)
+		}
+	}
+	run("git", "init", "-q", "-b", "main")
+	run("git", "config", "user.email", "test@test.com")
+	run("git", "config", "user.name", "Test")
+	return run
+}
+
+func setupHotspotRepo(t *testing.T) {
+	t.Helper()
+	run := initTestRepo(t)
+
+	hot := "package p\n\nfunc a() {\n\treturn\n}\n\nfunc b() {\n\treturn\n}\n"
+	os.WriteFile("hot.go", []byte(hot), 0644)
+	os.WriteFile("cold.go", []byte("package p\n\nvar x = 1\n"), 0644)
+	run("git", "add", ".")
+	run("git", "commit", "-qm", "initial")
+
+	for _, body := range []string{"\tx := 1\n\t_ = x\n", "\tx := 2\n\t_ = x\n"} {
+		edited := strings.Replace(hot, "func b() {\n\treturn\n", "func b() {\n"+body, 1)
+		os.WriteFile("hot.go", []byte(edited), 0644)
+		run("git", "commit", "-qam", "edit b")
+	}
+}
+
+func TestFileHotspots(t *testing.T) {
+	setupHotspotRepo(t)
+
+	spots, err := FileHotspots(nil, HotspotOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spots) != 2 || spots[0].Path != "hot.go" {
+		t.Fatalf("spots = %+v", spots)
+	}
+	if spots[0].Commits != 3 || spots[0].Score != 3*spots[0].Size {
+		t.Errorf("hot.go = %+v", spots[0])
+	}
+}
+
+func TestFunctionHotspots(t *testing.T) {
+	setupHotspotRepo(t)
+
+	spots, err := FunctionHotspots(nil, HotspotOptions{Glob: "*.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spots) == 0 || spots[0].Name != "b" {
+		t.Fatalf("spots = %+v", spots)
+	}
+	if spots[0].Commits != 3 {
+		t.Errorf("b commits = %d, want 3", spots[0].Commits)
+	}
+}
+
+func TestRunHotspots_Top(t *testing.T) {
+	setupHotspotRepo(t)
+
+	var buf bytes.Buffer
+	if err := RunHotspots(&buf, nil, HotspotOptions{Top: 1}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "hot.go") || strings.Contains(out, "cold.go") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	if !strings.Contains(out, "(1 of 2 shown)") {
+		t.Errorf("missing shown line:\n%s", out)
+	}
+}
@@ -0,0 +1,145 @@
+package metrics
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Language describes the comment syntax used to classify source lines.
+type Language struct {
+	Name          string
+	LineComments  []string
+	BlockComments [][2]string
+}
+
+var (
+	cStyle    = Language{LineComments: []string{"//"}, BlockComments: [][2]string{{"/*", "*/"}}}
+	hashStyle = Language{LineComments: []string{"#"}}
+)
+
+func lang(name string, base Language) Language {
+	base.Name = name
+	return base
+}
+
+// Languages maps file extensions to their comment syntax.
+var Languages = map[string]Language{
+	".go":    lang("Go", cStyle),
+	".rs":    lang("Rust", cStyle),
+	".js":    lang("JavaScript", cStyle),
+	".jsx":   lang("JavaScript", cStyle),
+	".mjs":   lang("JavaScript", cStyle),
+	".cjs":   lang("JavaScript", cStyle),
+	".ts":    lang("TypeScript", cStyle),
+	".tsx":   lang("TypeScript", cStyle),
+	".c":     lang("C", cStyle),
+	".h":     lang("C", cStyle),
+	".cc":    lang("C++", cStyle),
+	".cpp":   lang("C++", cStyle),
+	".hpp":   lang("C++", cStyle),
+	".java":  lang("Java", cStyle),
+	".kt":    lang("Kotlin", cStyle),
+	".swift": lang("Swift", cStyle),
+	".cs":    lang("C#", cStyle),
+	".scala": lang("Scala", cStyle),
+	".proto": lang("Protobuf", cStyle),
+	".css":   {Name: "CSS", BlockComments: [][2]string{{"/*", "*/"}}},
+	".scss":  lang("SCSS", cStyle),
+	".py": {Name: "Python", LineComments: []string{"#"},
+		BlockComments: [][2]string{{`"""`, `"""`}, {"'''", "'''"}}},
+	".rb": {Name: "Ruby", LineComments: []string{"#"},
+		BlockComments: [][2]string{{"=begin", "=end"}}},
+	".sh":   lang("Shell", hashStyle),
+	".bash": lang("Shell", hashStyle),
+	".zsh":  lang("Shell", hashStyle),
+	".fish": lang("Shell", hashStyle),
+	".yml":  lang("YAML", hashStyle),
+	".yaml": lang("YAML", hashStyle),
+	".toml": lang("TOML", hashStyle),
+	".pl":   lang("Perl", hashStyle),
+	".r":    lang("R", hashStyle),
+	".mk":   lang("Makefile", hashStyle),
+	".sql": {Name: "SQL", LineComments: []string{"--"},
+		BlockComments: [][2]string{{"/*", "*/"}}},
+	".lua": {Name: "Lua", LineComments: []string{"--"},
+		BlockComments: [][2]string{{"--[[", "]]"}}},
+	".hs": {Name: "Haskell", LineComments: []string{"--"},
+		BlockComments: [][2]string{{"{-", "-}"}}},
+	".html": {Name: "HTML", BlockComments: [][2]string{{"<!--", "-->"}}},
+	".xml":  {Name: "XML", BlockComments: [][2]string{{"<!--", "-->"}}},
+	".md":   {Name: "Markdown", BlockComments: [][2]string{{"<!--", "-->"}}},
+	".vim":  {Name: "Vim script", LineComments: []string{`"`}},
+}
+
+// languageFiles maps extensionless file names to languages.
+var languageFiles = map[string]Language{
+	"Makefile":    lang("Makefile", hashStyle),
+	"Dockerfile":  lang("Dockerfile", hashStyle),
+	"Rakefile":    lang("Ruby", hashStyle),
+	"Gemfile":     lang("Ruby", hashStyle),
+	"CMakeLists":  lang("CMake", hashStyle),
+	".gitignore":  lang("gitignore", hashStyle),
+	".bashrc":     lang("Shell", hashStyle),
+	".zshrc":      lang("Shell", hashStyle),
+	"Jenkinsfile": lang("Groovy", cStyle),
+}
+
+// LanguageFor returns the language of path by file name, then extension.
+func LanguageFor(path string) (Language, bool) {
+	base := filepath.Base(path)
+	if l, ok := languageFiles[strings.TrimSuffix(base, ".txt")]; ok {
+		return l, true
+	}
+	l, ok := Languages[strings.ToLower(filepath.Ext(base))]
+	return l, ok
+}
+
+// lineClassifier tracks block-comment state across the lines of one file.
+type lineClassifier struct {
+	lang    Language
+	inBlock string // end token of the open block comment, if any
+}
+
+// classify reports whether a non-blank line is code (as opposed to comment).
+// A line with any code outside comments counts as code. String literals are
+// not parsed, so comment tokens inside strings can misclassify a line.
+func (c *lineClassifier) classify(line string) (isCode bool) {
+	rest := strings.TrimSpace(line)
+	for rest != "" {
+		if c.inBlock != "" {
+			idx := strings.Index(rest, c.inBlock)
+			if idx < 0 {
+				return isCode
+			}
+			rest = strings.TrimSpace(rest[idx+len(c.inBlock):])
+			c.inBlock = ""
+			continue
+		}
+
+		start, end, pos := c.nextBlockStart(rest)
+		for _, lc := range c.lang.LineComments {
+			if i := strings.Index(rest, lc); i >= 0 && (pos < 0 || i < pos) {
+				return isCode || strings.TrimSpace(rest[:i]) != ""
+			}
+		}
+		if pos < 0 {
+			return true
+		}
+		if strings.TrimSpace(rest[:pos]) != "" {
+			isCode = true
+		}
+		rest = rest[pos+len(start):]
+		c.inBlock = end
+	}
+	return isCode
+}
+
+func (c *lineClassifier) nextBlockStart(s string) (start, end string, pos int) {
+	pos = -1
+	for _, bc := range c.lang.BlockComments {
+		if i := strings.Index(s, bc[0]); i >= 0 && (pos < 0 || i < pos) {
+			start, end, pos = bc[0], bc[1], i
+		}
+	}
+	return start, end, pos
+}
@@ -8,12 +8,73 @@ import (
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strings"
 )
 
 var DefaultTestMarkers = map[string]string{
 	".rs": `^#\[cfg\(test\)\]`,
 }
 
+// DefaultTestFiles lists, per extension, the patterns that make a whole file
+// test code: globs match the file name, and entries ending in "/" match any
+// directory in the path.
+var DefaultTestFiles = map[string][]string{
+	".go":   {"*_test.go"},
+	".py":   {"test_*.py", "*_test.py", "conftest.py", "tests/", "test/"},
+	".js":   {"*.test.js", "*.spec.js", "__tests__/"},
+	".jsx":  {"*.test.jsx", "*.spec.jsx", "__tests__/"},
+	".mjs":  {"*.test.mjs", "*.spec.mjs", "__tests__/"},
+	".ts":   {"*.test.ts", "*.spec.ts", "__tests__/"},
+	".tsx":  {"*.test.tsx", "*.spec.tsx", "__tests__/"},
+	".rs":   {"tests/", "benches/"},
+	".rb":   {"*_spec.rb", "*_test.rb", "spec/", "test/"},
+	".java": {"*Test.java", "src/test/"},
+}
+
+// IsTestFile reports whether path is test code as a whole per DefaultTestFiles.
+func IsTestFile(path string) bool {
+	return IsTestFileUnder("", path)
+}
+
+// IsTestFileUnder is IsTestFile matching test directories only below root,
+// so that everything in a checkout under ~/test is not test code.
+func IsTestFileUnder(root, path string) bool {
+	rel := path
+	if root != "" {
+		if r, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(r, "..") {
+			rel = r
+		}
+	}
+	slashed := "/" + filepath.ToSlash(filepath.Clean(rel))
+	base := filepath.Base(path)
+	for _, pat := range DefaultTestFiles[filepath.Ext(path)] {
+		if strings.HasSuffix(pat, "/") {
+			if strings.Contains(filepath.Dir(slashed)+"/", "/"+pat) {
+				return true
+			}
+		} else if matched, _ := filepath.Match(pat, base); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// walkRoot returns the deepest of the path arguments that file was found
+// under, or "" for a file named explicitly.
+func walkRoot(paths []string, file string) string {
+	root, best := "", ""
+	for _, p := range paths {
+		r, err := filepath.Rel(p, file)
+		if err != nil || r == "." || strings.HasPrefix(r, "..") {
+			continue
+		}
+		if root == "" || len(r) < len(best) {
+			root, best = p, r
+		}
+	}
+	return root
+}
+
 func CountLOC(path string, marker string) (int, error) {
 	f, err := os.Open(path)
 	if err != nil {
@@ -40,13 +101,92 @@ func CountLOC(path string, marker string) (int, error) {
 	return count, scanner.Err()
 }
 
+// CountLOCAutoDetect counts non-test lines using the default marker for the
+// file's extension; whole test files count as zero.
 func CountLOCAutoDetect(path string, explicitMarker string) (int, error) {
-	marker := explicitMarker
-	if marker == "" {
-		ext := filepath.Ext(path)
-		marker = DefaultTestMarkers[ext]
+	if IsTestFile(path) {
+		return 0, nil
 	}
-	return CountLOC(path, marker)
+	return CountLOC(path, markerForFile(path, explicitMarker))
+}
+
+func markerForFile(path string, explicit string) string {
+	if explicit != "" {
+		return explicit
+	}
+	return DefaultTestMarkers[filepath.Ext(path)]
+}
+
+// LineCounts classifies the lines of a file. Test holds code lines in test
+// files or after the test marker; Code holds only production code.
+type LineCounts struct {
+	Code    int
+	Comment int
+	Blank   int
+	Test    int
+}
+
+func (c LineCounts) Total() int {
+	return c.Code + c.Comment + c.Blank + c.Test
+}
+
+func (c *LineCounts) Add(o LineCounts) {
+	c.Code += o.Code
+	c.Comment += o.Comment
+	c.Blank += o.Blank
+	c.Test += o.Test
+}
+
+// CountLines classifies each line of path as code, comment or blank using
+// the comment syntax of its language; code from the first marker match on
+// counts as test. Files of unknown language have no comments.
+func CountLines(path string, marker string) (LineCounts, error) {
+	return countLines(path, marker, false)
+}
+
+func countLines(path string, marker string, testFile bool) (LineCounts, error) {
+	var c LineCounts
+	f, err := os.Open(path)
+	if err != nil {
+		return c, err
+	}
+	defer f.Close()
+
+	var markerRe *regexp.Regexp
+	if marker != "" {
+		markerRe, err = regexp.Compile(marker)
+		if err != nil {
+			return c, err
+		}
+	}
+
+	lang, _ := LanguageFor(path)
+	cl := lineClassifier{lang: lang}
+	inTest := testFile
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !inTest && markerRe != nil && markerRe.MatchString(line) {
+			inTest = true
+		}
+		switch {
+		case strings.TrimSpace(line) == "" && cl.inBlock == "":
+			c.Blank++
+		case !cl.classify(line):
+			c.Comment++
+		case inTest:
+			c.Test++
+		default:
+			c.Code++
+		}
+	}
+	return c, scanner.Err()
+}
+
+// CountLinesAutoDetect is CountLines with the default marker for the file's
+// extension, counting whole test files (see IsTestFile) as test.
+func CountLinesAutoDetect(path string, explicitMarker string) (LineCounts, error) {
+	return countLines(path, markerForFile(path, explicitMarker), IsTestFile(path))
 }
 
 func ResolveFiles(paths []string, globPattern string, excludePattern string) ([]string, error) {
@@ -85,18 +225,11 @@ func ResolveFiles(paths []string, globPattern string, excludePattern string) ([]
 		if pattern == "" {
 			pattern = "*"
 		}
-		err = filepath.Walk(p, func(path string, info os.FileInfo, err error) error {
-			if err != nil || info.IsDir() {
-				return err
-			}
-			if matchGlob(path, pattern) {
-				files = append(files, path)
-			}
-			return nil
-		})
+		walked, err := walkFiles(p, pattern)
 		if err != nil {
 			return nil, err
 		}
+		files = append(files, walked...)
 	}
 
 	if excludeRe != nil {
@@ -117,23 +250,51 @@ func matchGlob(path, pattern string) bool {
 	return matched
 }
 
-func RunLOC(w io.Writer, paths []string, globPattern, excludePattern, marker string) error {
-	files, err := ResolveFiles(paths, globPattern, excludePattern)
+// LOCOptions selects files and output for RunLOC.
+type LOCOptions struct {
+	Glob    string
+	Exclude string
+	Marker  string
+	// Simple prints one line count per file up to the test marker, without
+	// the code/comment/blank breakdown.
+	Simple bool
+	// TestRatio adds production vs test code per package.
+	TestRatio bool
+	// By groups files into subtotals: "dir", "ext" or "lang". Depth implies
+	// "dir".
+	By string
+	// Depth limits how many directory levels "dir" grouping shows; 0 is
+	// unlimited.
+	Depth int
+	// Top keeps the N largest files, or the N largest groups per level.
+	Top int
+}
+
+func RunLOC(w io.Writer, paths []string, opts LOCOptions) error {
+	files, err := ResolveFiles(paths, opts.Glob, opts.Exclude)
 	if err != nil {
 		return err
 	}
 	if len(files) == 0 {
 		return fmt.Errorf("No files found.")
 	}
+	if !opts.Simple {
+		return runLOCBreakdown(w, paths, files, opts)
+	}
 
-	total := 0
-	for _, f := range files {
-		n, err := CountLOCAutoDetect(f, marker)
-		if err != nil {
-			return err
+	counts, err := mapFiles(files, func(f string) (int, error) {
+		if IsTestFileUnder(walkRoot(paths, f), f) {
+			return 0, nil
 		}
-		total += n
-		fmt.Fprintf(w, "%6d %s\n", n, f)
+		return CountLOC(f, markerForFile(f, opts.Marker))
+	})
+	if err != nil {
+		return err
+	}
+	total := 0
+	for i, f := range files {
+		total += counts[i]
+		fmt.Fprintf(w, "%6d %s\n", counts[i], f)
 	}
 
 	if len(files) > 1 {
@@ -141,3 +302,121 @@ func RunLOC(w io.Writer, paths []string, globPattern, excludePattern, marker str
 	}
 	return nil
 }
+
+const locHeader = "%6s %7s %6s %6s %s\n"
+const locRow = "%6d %7d %6d %6d %s\n"
+
+// fileLines is the line breakdown of one file.
+type fileLines struct {
+	path   string
+	counts LineCounts
+}
+
+func runLOCBreakdown(w io.Writer, paths, files []string, opts LOCOptions) error {
+	by := opts.By
+	if by == "" && opts.Depth > 0 {
+		by = "dir"
+	}
+	switch by {
+	case "", "dir", "ext", "lang":
+	default:
+		return fmt.Errorf("unknown grouping %q (want dir, ext or lang)", by)
+	}
+
+	counted, err := mapFiles(files, func(f string) (fileLines, error) {
+		c, err := countLines(f, markerForFile(f, opts.Marker), IsTestFileUnder(walkRoot(paths, f), f))
+		return fileLines{f, c}, err
+	})
+	if err != nil {
+		return err
+	}
+
+	byPkg := make(map[string]*LineCounts)
+	var total LineCounts
+	for _, fl := range counted {
+		f, c := fl.path, fl.counts
+		total.Add(c)
+
+		pkg := packageOf(f)
+		if byPkg[pkg] == nil {
+			byPkg[pkg] = &LineCounts{}
+		}
+		byPkg[pkg].Add(c)
+	}
+	if opts.TestRatio {
+		defer writeTestRatios(w, byPkg, total)
+	}
+
+	switch by {
+	case "dir":
+		writeLOCTree(w, counted, opts.Depth, opts.Top)
+	case "ext", "lang":
+		writeLOCGroups(w, counted, by, opts.Top)
+	default:
+		writeLOCFiles(w, counted, opts.Top)
+		if len(files) == 1 {
+			return nil
+		}
+		fmt.Fprintln(w)
+		writeLOCGroupRows(w, counted, "lang", 0)
+	}
+	fmt.Fprintf(w, locRow, total.Code, total.Comment, total.Blank, total.Test, "total")
+	return nil
+}
+
+// writeLOCFiles prints one row per file, or the top N files by code lines.
+func writeLOCFiles(w io.Writer, counted []fileLines, top int) {
+	fmt.Fprintf(w, locHeader, "code", "comment", "blank", "test", "file")
+	rows := counted
+	if top > 0 && len(rows) > top {
+		rows = append([]fileLines(nil), counted...)
+		sort.SliceStable(rows, func(i, j int) bool { return rows[i].counts.Code > rows[j].counts.Code })
+		rows = rows[:top]
+	}
+	for _, fl := range rows {
+		c := fl.counts
+		fmt.Fprintf(w, locRow, c.Code, c.Comment, c.Blank, c.Test, fl.path)
+	}
+	if len(rows) < len(counted) {
+		fmt.Fprintf(w, "(%d of %d files shown)\n", len(rows), len(counted))
+	}
+}
+
+// packageOf groups a file with its package: its directory, with test
+// directories (tests/, __tests__/, ...) folded into their parent.
+func packageOf(path string) string {
+	dir := filepath.Dir(path)
+	for {
+		switch filepath.Base(dir) {
+		case "tests", "test", "__tests__", "spec":
+			dir = filepath.Dir(dir)
+			continue
+		}
+		return dir
+	}
+}
+
+func testRatio(c LineCounts) string {
+	if c.Code == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", float64(c.Test)/float64(c.Code))
+}
+
+func writeTestRatios(w io.Writer, byPkg map[string]*LineCounts, total LineCounts) {
+	pkgs := make([]string, 0, len(byPkg))
+	for p := range byPkg {
+		pkgs = append(pkgs, p)
+	}
+	sort.Strings(pkgs)
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%6s %6s %6s %s\n", "prod", "test", "ratio", "package")
+	for _, p := range pkgs {
+		c := byPkg[p]
+		fmt.Fprintf(w, "%6d %6d %6s %s\n", c.Code, c.Test, testRatio(*c), p)
+	}
+	if len(pkgs) > 1 {
+		fmt.Fprintf(w, "%6d %6d %6s %s\n", total.Code, total.Test, testRatio(total), "total")
+	}
+}
@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"bytes"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -92,7 +93,7 @@ func TestRunLOC_Output(t *testing.T) {
 	os.WriteFile(filepath.Join(dir, "b.txt"), []byte("one\ntwo\nthree\n"), 0644)
 
 	var buf bytes.Buffer
-	err := RunLOC(&buf, []string{filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")}, "", "", "")
+	err := RunLOC(&buf, []string{filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")}, LOCOptions{Simple: true})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -107,3 +108,265 @@ func TestRunLOC_Output(t *testing.T) {
 		t.Errorf("missing total in:\n%s", out)
 	}
 }
+
+func TestCountLines_Rust(t *testing.T) {
+	c, err := CountLinesAutoDetect("../../testdata/fixtures/sample.rs", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := LineCounts{Code: 6, Comment: 1, Blank: 3, Test: 5}
+	if c != want {
+		t.Errorf("got %+v, want %+v", c, want)
+	}
+}
+
+func TestCountLines_BlockComments(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.go")
+	os.WriteFile(path, []byte(`package a
+
+/*
+Doc block
+
+still doc
+*/
+var x = 1 // trailing
+/* one-liner */
+var y = /* inline */ 2
+/* open */ var z = 3
+`), 0644)
+
+	c, err := CountLines(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := LineCounts{Code: 4, Comment: 6, Blank: 1}
+	if c != want {
+		t.Errorf("got %+v, want %+v", c, want)
+	}
+}
+
+func TestCountLines_PythonDocstring(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.py")
+	os.WriteFile(path, []byte("def f():\n    \"\"\"Docstring\n    more.\n    \"\"\"\n    # note\n    return 1\n"), 0644)
+
+	c, err := CountLines(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := LineCounts{Code: 2, Comment: 4}
+	if c != want {
+		t.Errorf("got %+v, want %+v", c, want)
+	}
+}
+
+func TestCountLines_UnknownLanguage(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.txt")
+	os.WriteFile(path, []byte("# not a comment\n\n// nor this\n"), 0644)
+
+	c, err := CountLines(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Code != 2 || c.Blank != 1 || c.Comment != 0 {
+		t.Errorf("got %+v, want 2 code, 1 blank", c)
+	}
+}
+
+func TestLanguageFor(t *testing.T) {
+	cases := map[string]string{
+		"main.go":        "Go",
+		"x/Makefile":     "Makefile",
+		"ci.yml":         "YAML",
+		"build.sh":       "Shell",
+		"App.TSX":        "TypeScript",
+		"CMakeLists.txt": "CMake",
+	}
+	for path, want := range cases {
+		l, ok := LanguageFor(path)
+		if !ok || l.Name != want {
+			t.Errorf("LanguageFor(%q) = %q, %v; want %q", path, l.Name, ok, want)
+		}
+	}
+	if _, ok := LanguageFor("data.bin"); ok {
+		t.Error("unexpected language for data.bin")
+	}
+}
+
+func TestRunLOC_Breakdown(t *testing.T) {
+	var buf bytes.Buffer
+	err := RunLOC(&buf, []string{"../../testdata/fixtures/sample.rs", "../../testdata/fixtures/sample.py"}, LOCOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"  code comment  blank   test file",
+		"     6       1      3      5 ../../testdata/fixtures/sample.rs",
+		"     7       0      3      0 Python (1 files)",
+		"    13       1      6      5 total",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestIsTestFile(t *testing.T) {
+	cases := map[string]bool{
+		"pkg/a_test.go":              true,
+		"pkg/a.go":                   false,
+		"app/test_models.py":         true,
+		"app/tests/helpers.py":       true,
+		"app/models.py":              false,
+		"web/Button.test.tsx":        true,
+		"web/__tests__/util.ts":      true,
+		"web/util.ts":                false,
+		"crate/tests/integration.rs": true,
+		"crate/src/lib.rs":           false,
+		"contest.py":                 false,
+	}
+	for path, want := range cases {
+		if got := IsTestFile(path); got != want {
+			t.Errorf("IsTestFile(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestRunLOC_TestDirAboveRoot(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "test", "repo")
+	os.MkdirAll(filepath.Join(dir, "tests"), 0755)
+	os.WriteFile(filepath.Join(dir, "app.py"), []byte("a = 1\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "tests", "helpers.py"), []byte("b = 2\n"), 0644)
+
+	if !IsTestFileUnder(dir, filepath.Join(dir, "tests", "helpers.py")) || IsTestFileUnder(dir, filepath.Join(dir, "app.py")) {
+		t.Error("IsTestFileUnder matched the wrong files")
+	}
+
+	var buf bytes.Buffer
+	if err := RunLOC(&buf, []string{dir}, LOCOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "     1       0      0      1 total") {
+		t.Errorf("test dir above the walked root counted as test:\n%s", buf.String())
+	}
+}
+
+func TestCountLinesAutoDetect_TestFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a_test.go")
+	os.WriteFile(path, []byte("package a\n\n// helper\nfunc TestA() {}\n"), 0644)
+
+	c, err := CountLinesAutoDetect(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := LineCounts{Test: 2, Comment: 1, Blank: 1}
+	if c != want {
+		t.Errorf("got %+v, want %+v", c, want)
+	}
+	if n, _ := CountLOCAutoDetect(path, ""); n != 0 {
+		t.Errorf("simple count of test file = %d, want 0", n)
+	}
+}
+
+func TestRunLOC_TestRatio(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "pkg", "tests"), 0755)
+	os.WriteFile(filepath.Join(dir, "pkg", "lib.py"), []byte("a = 1\nb = 2\nc = 3\nd = 4\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "pkg", "tests", "test_lib.py"), []byte("assert 1\nassert 2\n"), 0644)
+
+	var buf bytes.Buffer
+	if err := RunLOC(&buf, []string{dir}, LOCOptions{TestRatio: true}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	want := fmt.Sprintf("%6d %6d %6s %s", 4, 2, "0.50", filepath.Join(dir, "pkg"))
+	if !strings.Contains(out, want) {
+		t.Errorf("missing %q in:\n%s", want, out)
+	}
+}
+
+func TestRunLOC_ByDir(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "big", "inner"), 0755)
+	os.MkdirAll(filepath.Join(dir, "small"), 0755)
+	os.WriteFile(filepath.Join(dir, "big", "a.py"), []byte("a = 1\nb = 2\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "big", "inner", "b.py"), []byte("c = 3\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "small", "c.py"), []byte("d = 4\n"), 0644)
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	var buf bytes.Buffer
+	if err := RunLOC(&buf, []string{"big", "small"}, LOCOptions{By: "dir", Top: 1}); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"  code comment  blank   test dir",
+		"     3       0      0      0 big/ (2 files)",
+		"     1       0      0      0   inner/ (1 files)",
+		"     1       0      0      0 ... 1 more (1 files)",
+		"     4       0      0      0 total",
+		"",
+	}, "\n")
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	buf.Reset()
+	if err := RunLOC(&buf, []string{"big", "small"}, LOCOptions{Depth: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "inner/") {
+		t.Errorf("depth 1 shows nested dir:\n%s", buf.String())
+	}
+}
+
+func TestRunLOC_ByDirAbsolute(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "src", "a"), 0755)
+	os.MkdirAll(filepath.Join(dir, "src", "b"), 0755)
+	os.WriteFile(filepath.Join(dir, "src", "a", "a.py"), []byte("a = 1\nb = 2\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "src", "b", "b.py"), []byte("c = 3\n"), 0644)
+
+	var buf bytes.Buffer
+	if err := RunLOC(&buf, []string{filepath.Join(dir, "src")}, LOCOptions{Depth: 2}); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"  code comment  blank   test dir",
+		"     3       0      0      0 src/ (2 files)",
+		"     2       0      0      0   a/ (1 files)",
+		"     1       0      0      0   b/ (1 files)",
+		"     3       0      0      0 total",
+		"",
+	}, "\n")
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRunLOC_ByExt(t *testing.T) {
+	var buf bytes.Buffer
+	err := RunLOC(&buf, []string{"../../testdata/fixtures/sample.rs", "../../testdata/fixtures/sample.py"}, LOCOptions{By: "ext", Top: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"     7       0      3      0 .py (1 files)",
+		"     6       1      3      5 ... 1 more (1 files)",
+		"    13       1      6      5 total",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	if err := RunLOC(&buf, []string{"../../testdata/fixtures/sample.rs"}, LOCOptions{By: "size"}); err == nil {
+		t.Error("expected error for unknown grouping")
+	}
+}
@@ -0,0 +1,178 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// locGroup is a subtotal of the files sharing a directory, extension or
+// language.
+type locGroup struct {
+	name     string
+	files    int
+	counts   LineCounts
+	children map[string]*locGroup
+}
+
+func (g *locGroup) add(c LineCounts) {
+	g.files++
+	g.counts.Add(c)
+}
+
+func (g *locGroup) child(name string) *locGroup {
+	if g.children == nil {
+		g.children = make(map[string]*locGroup)
+	}
+	c := g.children[name]
+	if c == nil {
+		c = &locGroup{name: name}
+		g.children[name] = c
+	}
+	return c
+}
+
+// largest returns the groups sorted by code lines, largest first.
+func largest(groups map[string]*locGroup) []*locGroup {
+	out := make([]*locGroup, 0, len(groups))
+	for _, g := range groups {
+		out = append(out, g)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].counts.Code != out[j].counts.Code {
+			return out[i].counts.Code > out[j].counts.Code
+		}
+		return out[i].name < out[j].name
+	})
+	return out
+}
+
+func groupKey(path, by string) string {
+	if by == "ext" {
+		if ext := strings.ToLower(filepath.Ext(path)); ext != "" {
+			return ext
+		}
+		return "(none)"
+	}
+	if l, ok := LanguageFor(path); ok {
+		return l.Name
+	}
+	return "Other"
+}
+
+// writeLOCGroups prints a header and one subtotal per extension or language.
+func writeLOCGroups(w io.Writer, counted []fileLines, by string, top int) {
+	fmt.Fprintf(w, locHeader, "code", "comment", "blank", "test", by)
+	writeLOCGroupRows(w, counted, by, top)
+}
+
+func writeLOCGroupRows(w io.Writer, counted []fileLines, by string, top int) {
+	var root locGroup
+	for _, fl := range counted {
+		root.child(groupKey(fl.path, by)).add(fl.counts)
+	}
+	writeLOCLevel(w, largest(root.children), 0, top, func(g *locGroup) string {
+		return fmt.Sprintf("%s (%d files)", g.name, g.files)
+	})
+}
+
+// writeLOCTree prints directory subtotals as an indented tree, up to depth
+// levels from the common root (0 is unlimited). Files are folded into
+// their deepest shown directory.
+func writeLOCTree(w io.Writer, counted []fileLines, depth, top int) {
+	split := make([][]string, len(counted))
+	for i, fl := range counted {
+		split[i] = dirParts(fl.path)
+	}
+	// The directories above the common root (such as those of an absolute
+	// path argument) are dropped; the root itself is the top level.
+	skip := max(commonPrefixLen(split)-1, 0)
+
+	var root locGroup
+	for i, fl := range counted {
+		g := &root
+		for j, part := range split[i][skip:] {
+			if depth > 0 && j >= depth {
+				break
+			}
+			g = g.child(part)
+			g.add(fl.counts)
+		}
+	}
+
+	fmt.Fprintf(w, locHeader, "code", "comment", "blank", "test", "dir")
+	var visit func(groups map[string]*locGroup, level int)
+	visit = func(groups map[string]*locGroup, level int) {
+		sorted := largest(groups)
+		shown := sorted
+		if top > 0 && len(shown) > top {
+			shown = shown[:top]
+		}
+		for _, g := range shown {
+			c := g.counts
+			label := fmt.Sprintf("%s%s/ (%d files)", strings.Repeat("  ", level), strings.TrimSuffix(g.name, "/"), g.files)
+			fmt.Fprintf(w, locRow, c.Code, c.Comment, c.Blank, c.Test, label)
+			visit(g.children, level+1)
+		}
+		writeLOCRest(w, sorted[len(shown):], level)
+	}
+	visit(root.children, 0)
+}
+
+// dirParts splits the directory of path into its components; files in the
+// working directory are under ".".
+func dirParts(path string) []string {
+	parts := strings.Split(filepath.ToSlash(filepath.Dir(path)), "/")
+	if parts[0] == "" {
+		parts[0] = "/"
+	}
+	return parts
+}
+
+// commonPrefixLen counts the leading components all of paths share.
+func commonPrefixLen(paths [][]string) int {
+	if len(paths) == 0 {
+		return 0
+	}
+	n := len(paths[0])
+	for _, p := range paths[1:] {
+		n = min(n, len(p))
+		for i := 0; i < n; i++ {
+			if p[i] != paths[0][i] {
+				n = i
+				break
+			}
+		}
+	}
+	return n
+}
+
+// writeLOCLevel prints the top groups of one level, then one row summing
+// the rest.
+func writeLOCLevel(w io.Writer, groups []*locGroup, level, top int, label func(*locGroup) string) {
+	shown := groups
+	if top > 0 && len(shown) > top {
+		shown = shown[:top]
+	}
+	for _, g := range shown {
+		c := g.counts
+		fmt.Fprintf(w, locRow, c.Code, c.Comment, c.Blank, c.Test, label(g))
+	}
+	writeLOCRest(w, groups[len(shown):], level)
+}
+
+func writeLOCRest(w io.Writer, rest []*locGroup, level int) {
+	if len(rest) == 0 {
+		return
+	}
+	var c LineCounts
+	files := 0
+	for _, g := range rest {
+		c.Add(g.counts)
+		files += g.files
+	}
+	label := fmt.Sprintf("%s... %d more (%d files)", strings.Repeat("  ", level), len(rest), files)
+	fmt.Fprintf(w, locRow, c.Code, c.Comment, c.Blank, c.Test, label)
+}
@@ -0,0 +1,289 @@
+package metrics
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Symbol is one declaration in a file outline. Methods and nested
+// declarations are Children of their type, class, impl or trait.
+type Symbol struct {
+	Kind     string
+	Name     string
+	Start    int
+	End      int
+	Children []Symbol
+}
+
+// OutlineFile lists the declarations of a file: exactly via go/ast for Go,
+// heuristically for Python, Rust, TypeScript and JavaScript.
+func OutlineFile(path string) ([]Symbol, error) {
+	ext := filepath.Ext(path)
+	if ext == ".go" {
+		return GoOutline(path)
+	}
+	patterns, ok := outlinePatterns[ext]
+	if !ok {
+		return nil, nil
+	}
+	lines, err := readLines(path)
+	if err != nil {
+		return nil, err
+	}
+	return heuristicOutline(lines, patterns, ext == ".py"), nil
+}
+
+// GoOutline lists the types, functions, constants and variables of a Go
+// file, with methods grouped under their receiver type.
+func GoOutline(path string) ([]Symbol, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	line := func(p token.Pos) int { return fset.Position(p).Line }
+
+	var syms []Symbol
+	methods := make(map[string][]Symbol)
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			sym := Symbol{Kind: "func", Name: d.Name.Name, Start: line(d.Pos()), End: line(d.End())}
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				sym.Kind = "method"
+				recv := recvTypeName(d.Recv.List[0].Type)
+				methods[recv] = append(methods[recv], sym)
+				continue
+			}
+			syms = append(syms, sym)
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				start, end := line(spec.Pos()), line(spec.End())
+				if len(d.Specs) == 1 {
+					start, end = line(d.Pos()), line(d.End())
+				}
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					kind := "type"
+					switch s.Type.(type) {
+					case *ast.StructType:
+						kind = "struct"
+					case *ast.InterfaceType:
+						kind = "interface"
+					}
+					syms = append(syms, Symbol{Kind: kind, Name: s.Name.Name, Start: start, End: end})
+				case *ast.ValueSpec:
+					kind := "var"
+					if d.Tok == token.CONST {
+						kind = "const"
+					}
+					for _, n := range s.Names {
+						if n.Name != "_" {
+							syms = append(syms, Symbol{Kind: kind, Name: n.Name, Start: start, End: end})
+						}
+					}
+				}
+			}
+		}
+	}
+
+	for i := range syms {
+		if ms, ok := methods[syms[i].Name]; ok && syms[i].Kind != "func" {
+			syms[i].Children = ms
+			delete(methods, syms[i].Name)
+		}
+	}
+	// Methods on types declared in another file get a receiver entry.
+	for recv, ms := range methods {
+		syms = append(syms, Symbol{Kind: "receiver", Name: recv, Start: ms[0].Start, End: ms[len(ms)-1].End, Children: ms})
+	}
+	sort.SliceStable(syms, func(i, j int) bool { return syms[i].Start < syms[j].Start })
+	return syms, nil
+}
+
+// outlinePattern matches one kind of declaration; group 1 is the name.
+type outlinePattern struct {
+	kind string
+	re   *regexp.Regexp
+}
+
+var (
+	rustOutline = []outlinePattern{
+		{"struct", regexp.MustCompile(`^\s*(?:pub(?:\([\w:]+\))?\s+)?struct\s+(\w+)`)},
+		{"enum", regexp.MustCompile(`^\s*(?:pub(?:\([\w:]+\))?\s+)?enum\s+(\w+)`)},
+		{"trait", regexp.MustCompile(`^\s*(?:pub(?:\([\w:]+\))?\s+)?(?:unsafe\s+)?trait\s+(\w+)`)},
+		{"impl", regexp.MustCompile(`^\s*(?:unsafe\s+)?impl\b(?:<[^>]*>)?\s+(.+?)\s*(?:where\b.*)?\{?\s*$`)},
+		{"mod", regexp.MustCompile(`^\s*(?:pub(?:\([\w:]+\))?\s+)?mod\s+(\w+)`)},
+		{"type", regexp.MustCompile(`^\s*(?:pub(?:\([\w:]+\))?\s+)?type\s+(\w+)`)},
+		{"fn", regexp.MustCompile(`^\s*(?:pub(?:\([\w:]+\))?\s+)?(?:const\s+)?(?:async\s+)?(?:unsafe\s+)?fn\s+(\w+)`)},
+		// The ": type" keeps "const fn" out; fn is tried first anyway.
+		{"const", regexp.MustCompile(`^\s*(?:pub(?:\([\w:]+\))?\s+)?(?:const|static)\s+(?:mut\s+)?(\w+)\s*:`)},
+	}
+	pythonOutline = []outlinePattern{
+		{"class", regexp.MustCompile(`^\s*class\s+(\w+)`)},
+		{"def", regexp.MustCompile(`^\s*(?:async\s+)?def\s+(\w+)`)},
+	}
+	tsOutline = []outlinePattern{
+		{"class", regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:abstract\s+)?class\s+(\w+)`)},
+		{"interface", regexp.MustCompile(`^\s*(?:export\s+)?interface\s+(\w+)`)},
+		{"enum", regexp.MustCompile(`^\s*(?:export\s+)?(?:const\s+)?enum\s+(\w+)`)},
+		{"type", regexp.MustCompile(`^\s*(?:export\s+)?type\s+(\w+)`)},
+		{"function", regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:async\s+)?function\*?\s+(\w+)`)},
+		{"const", regexp.MustCompile(`^(?:export\s+)?(?:const|let|var)\s+(\w+)`)},
+		{"method", regexp.MustCompile(`^\s+(?:(?:public|private|protected|static|readonly|async|override|get|set)\s+)*(\w+)\s*(?:<[^>]*>)?\(.*\)\s*(?::\s*[^{;=]+)?\{\s*$`)},
+	}
+)
+
+var outlinePatterns = map[string][]outlinePattern{
+	".rs":  rustOutline,
+	".py":  pythonOutline,
+	".ts":  tsOutline,
+	".tsx": tsOutline,
+	".js":  tsOutline,
+	".jsx": tsOutline,
+	".mjs": tsOutline,
+}
+
+// controlWords are keywords the TypeScript method pattern would otherwise
+// take for method names.
+var controlWords = map[string]bool{
+	"if": true, "for": true, "while": true, "switch": true, "catch": true,
+	"return": true, "function": true,
+}
+
+// heuristicOutline finds declarations line by line. Python blocks end at the
+// next line indented no deeper than the declaration; other languages end
+// at the matching closing brace. Declarations inside another one become its
+// children.
+func heuristicOutline(lines []string, patterns []outlinePattern, indented bool) []Symbol {
+	var flat []Symbol
+	for i, line := range lines {
+		for _, p := range patterns {
+			m := p.re.FindStringSubmatch(line)
+			if m == nil || controlWords[m[1]] {
+				continue
+			}
+			end := braceEnd(lines, i)
+			if indented {
+				end = indentEnd(lines, i)
+			}
+			flat = append(flat, Symbol{Kind: p.kind, Name: strings.TrimSpace(m[1]), Start: i + 1, End: end})
+			break
+		}
+	}
+	return nestSymbols(flat)
+}
+
+// nestSymbols turns a flat, start-ordered list into a tree by line
+// containment.
+func nestSymbols(flat []Symbol) []Symbol {
+	var out []Symbol
+	for i := 0; i < len(flat); {
+		sym := flat[i]
+		j := i + 1
+		for j < len(flat) && flat[j].Start <= sym.End {
+			j++
+		}
+		sym.Children = nestSymbols(flat[i+1 : j])
+		out = append(out, sym)
+		i = j
+	}
+	return out
+}
+
+// indentEnd returns the last non-blank line of the block opened at line i.
+func indentEnd(lines []string, i int) int {
+	base := indentWidth(lines[i])
+	end := i
+	for j := i + 1; j < len(lines); j++ {
+		if strings.TrimSpace(lines[j]) == "" {
+			continue
+		}
+		if indentWidth(lines[j]) <= base {
+			break
+		}
+		end = j
+	}
+	return end + 1
+}
+
+// braceEnd returns the line closing the first brace opened at or after line
+// i, or the line of a terminating semicolon that comes before any brace.
+// Braces inside strings and block comments are miscounted.
+func braceEnd(lines []string, i int) int {
+	depth, opened := 0, false
+	for j := i; j < len(lines); j++ {
+		code := lines[j]
+		if k := strings.Index(code, "//"); k >= 0 {
+			code = code[:k]
+		}
+		for _, c := range code {
+			switch c {
+			case '{':
+				depth++
+				opened = true
+			case '}':
+				depth--
+			case ';':
+				if !opened {
+					return j + 1
+				}
+			}
+			if opened && depth == 0 {
+				return j + 1
+			}
+		}
+	}
+	return len(lines)
+}
+
+func writeOutline(w io.Writer, syms []Symbol, depth int) {
+	for _, s := range syms {
+		fmt.Fprintf(w, "%s%d-%d %s %s\n", strings.Repeat("  ", depth+1), s.Start, s.End, s.Kind, s.Name)
+		writeOutline(w, s.Children, depth+1)
+	}
+}
+
+func RunOutline(w io.Writer, paths []string, globPattern, excludePath string) error {
+	files, err := ResolveFiles(paths, globPattern, excludePath)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("No files found.")
+	}
+
+	outlines, err := mapFiles(files, OutlineFile)
+	if err != nil {
+		return err
+	}
+	multi := len(files) > 1
+	for i, f := range files {
+		if len(outlines[i]) == 0 {
+			if _, ok := outlinePatterns[filepath.Ext(f)]; !ok && filepath.Ext(f) != ".go" {
+				fmt.Fprintf(os.Stderr, "No outline support for %s\n", f)
+				continue
+			}
+			if !multi {
+				return fmt.Errorf("No symbols found.")
+			}
+			continue
+		}
+		if multi {
+			fmt.Fprintf(w, "==> %s <==\n", f)
+		}
+		writeOutline(w, outlines[i], 0)
+		if multi {
+			fmt.Fprintln(w)
+		}
+	}
+	return nil
+}
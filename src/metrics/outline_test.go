@@ -0,0 +1,142 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func outlineString(syms []Symbol) string {
+	var buf bytes.Buffer
+	writeOutline(&buf, syms, 0)
+	return buf.String()
+}
+
+func TestGoOutline(t *testing.T) {
+	path := writeFixture(t, "o.go", `package p
+
+const Max = 3
+
+var (
+	a = 1
+	b = 2
+)
+
+type Shape interface {
+	Area() int
+}
+
+func (s *Square) Area() int { return s.n * s.n }
+
+type Square struct {
+	n int
+}
+
+func New() *Square {
+	return &Square{}
+}
+
+func (o Other) X() {}
+`)
+	syms, err := GoOutline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `  3-3 const Max
+  6-6 var a
+  7-7 var b
+  10-12 interface Shape
+  16-18 struct Square
+    14-14 method Area
+  20-22 func New
+  24-24 receiver Other
+    24-24 method X
+`
+	if got := outlineString(syms); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestHeuristicOutline_Rust(t *testing.T) {
+	path := writeFixture(t, "o.rs", `pub struct Point {
+    x: i32,
+}
+
+pub struct Unit;
+
+impl Display for Point {
+    fn fmt(&self) -> String {
+        format!("{}", self.x)
+    }
+}
+
+pub trait Shape {
+    fn area(&self) -> i32;
+}
+
+pub const fn new() -> i32 {
+    0
+}
+
+pub const MAX: i32 = 3;
+`)
+	syms, err := OutlineFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `  1-3 struct Point
+  5-5 struct Unit
+  7-11 impl Display for Point
+    8-10 fn fmt
+  13-15 trait Shape
+    14-14 fn area
+  17-19 fn new
+  21-21 const MAX
+`
+	if got := outlineString(syms); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestHeuristicOutline_PythonAndTS(t *testing.T) {
+	py := writeFixture(t, "o.py", "class A:\n    def f(self):\n        pass\n\n    def g(self):\n        pass\n\ndef top():\n    return 1\n")
+	syms, err := OutlineFile(py)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := outlineString(syms); got != "  1-6 class A\n    2-3 def f\n    5-6 def g\n  8-9 def top\n" {
+		t.Errorf("python outline:\n%s", got)
+	}
+
+	ts := writeFixture(t, "o.ts", `export interface Props {
+  name: string;
+}
+
+export class Widget {
+  private render(): string {
+    if (this.x) {
+      return "a";
+    }
+    return "b";
+  }
+}
+`)
+	syms, err = OutlineFile(ts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := outlineString(syms); !strings.Contains(got, "  5-12 class Widget\n    6-11 method render\n") || strings.Contains(got, " if") {
+		t.Errorf("ts outline:\n%s", got)
+	}
+}
+
+func TestRunOutline_Unsupported(t *testing.T) {
+	path := writeFixture(t, "x.txt", "plain text\n")
+	var buf bytes.Buffer
+	if err := RunOutline(&buf, []string{path}, "", ""); err != nil {
+		t.Errorf("RunOutline(x.txt) = %v, want the file skipped", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
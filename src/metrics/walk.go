@@ -0,0 +1,259 @@
+package metrics
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// DefaultSkipDirs are directory names never descended into when walking a
+// directory argument. Naming one explicitly as a path still includes it.
+var DefaultSkipDirs = []string{
+	".git", ".hg", ".svn",
+	"node_modules", "vendor", "target",
+	"__pycache__", ".venv",
+}
+
+// ExcludeNames are file and directory name globs skipped when walking, as
+// set by the exclude key of the config file.
+var ExcludeNames []string
+
+// walkFiles lists the files under root matching the glob pattern in
+// lexical order, skipping DefaultSkipDirs, ExcludeNames and anything
+// ignored by the .gitignore files of root, its subdirectories and its
+// parents up to the repository root.
+func walkFiles(root, pattern string) ([]string, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	ig := loadParentIgnores(absRoot)
+
+	var files []string
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		abs := filepath.Join(absRoot, rel)
+		if d.IsDir() {
+			if path == root {
+				ig.load(abs)
+				return nil
+			}
+			if skipDir(d.Name()) || ig.ignored(abs, true) {
+				return filepath.SkipDir
+			}
+			ig.load(abs)
+			return nil
+		}
+		if !excludedName(d.Name()) && !ig.ignored(abs, false) && matchGlob(path, pattern) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+func skipDir(name string) bool {
+	for _, s := range DefaultSkipDirs {
+		if name == s {
+			return true
+		}
+	}
+	return excludedName(name)
+}
+
+func excludedName(name string) bool {
+	for _, pattern := range ExcludeNames {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ignoreRule is one .gitignore pattern, scoped to the directory holding the
+// .gitignore file.
+type ignoreRule struct {
+	base     string
+	re       *regexp.Regexp
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// gitignore matches paths against the rules loaded so far; later rules win,
+// as in git.
+type gitignore struct {
+	rules []ignoreRule
+}
+
+// loadParentIgnores loads the .gitignore files above dir, outermost first,
+// stopping at the directory that holds .git.
+func loadParentIgnores(dir string) *gitignore {
+	ig := &gitignore{}
+	var parents []string
+	for d := dir; ; {
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			break
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			// Not inside a repository: only the walked tree's own files apply.
+			return ig
+		}
+		parents = append(parents, parent)
+		d = parent
+	}
+	for i := len(parents) - 1; i >= 0; i-- {
+		ig.load(parents[i])
+	}
+	return ig
+}
+
+// load adds the rules of dir/.gitignore, if any.
+func (g *gitignore) load(dir string) {
+	f, err := os.Open(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if rule, ok := parseIgnoreRule(dir, scanner.Text()); ok {
+			g.rules = append(g.rules, rule)
+		}
+	}
+}
+
+func parseIgnoreRule(base, line string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+	rule := ignoreRule{base: base}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		rule.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	re, err := regexp.Compile("^" + ignoreGlobToRegexp(line) + "$")
+	if err != nil {
+		return ignoreRule{}, false
+	}
+	rule.re = re
+	return rule, line != ""
+}
+
+// ignoreGlobToRegexp translates gitignore glob syntax: * and ? stay within
+// a path component, ** crosses components.
+func ignoreGlobToRegexp(glob string) string {
+	var sb strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '[':
+			if end := strings.IndexByte(glob[i+1:], ']'); end >= 0 {
+				class := glob[i+1 : i+1+end]
+				if strings.HasPrefix(class, "!") {
+					class = "^" + class[1:]
+				}
+				sb.WriteString("[" + class + "]")
+				i += end + 1
+				continue
+			}
+			sb.WriteString(`\[`)
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return sb.String()
+}
+
+// ignored reports whether the absolute path is ignored. Ignored directories
+// are never descended into, so a path's parents need not be checked.
+func (g *gitignore) ignored(path string, isDir bool) bool {
+	ignored := false
+	for _, r := range g.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		rel, err := filepath.Rel(r.base, path)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		subject := rel
+		if !r.anchored {
+			subject = rel[strings.LastIndex(rel, "/")+1:]
+		}
+		if r.re.MatchString(subject) {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
+
+// mapFiles runs fn over files on a bounded pool of workers and returns the
+// results in file order. The first error, in file order, is returned.
+func mapFiles[T any](files []string, fn func(path string) (T, error)) ([]T, error) {
+	results := make([]T, len(files))
+	errs := make([]error, len(files))
+
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(files) {
+		workers = len(files)
+	}
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i], errs[i] = fn(files[i])
+			}
+		}()
+	}
+	for i := range files {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,122 @@
+package metrics
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWalkFiles_SkipsAndGitignore(t *testing.T) {
+	dir := t.TempDir()
+	for _, f := range []string{
+		"a.go",
+		"gen/out.go",
+		"logs/app.log",
+		"keep/build/x.go",
+		"build/y.go",
+		"node_modules/dep/index.js",
+		"sub/b.go",
+		"sub/b_gen.go",
+		"sub/important.log",
+	} {
+		os.MkdirAll(filepath.Join(dir, filepath.Dir(f)), 0755)
+		os.WriteFile(filepath.Join(dir, f), []byte("x\n"), 0644)
+	}
+	os.Mkdir(filepath.Join(dir, ".git"), 0755)
+	os.WriteFile(filepath.Join(dir, ".git", "HEAD"), []byte("ref\n"), 0644)
+	os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("# comment\n*.log\n/build/\ngen/\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "sub", ".gitignore"), []byte("*_gen.go\n!important.log\n"), 0644)
+
+	files, err := walkFiles(dir, "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rel []string
+	for _, f := range files {
+		r, _ := filepath.Rel(dir, f)
+		rel = append(rel, filepath.ToSlash(r))
+	}
+	want := []string{".gitignore", "a.go", "keep/build/x.go", "sub/.gitignore", "sub/b.go", "sub/important.log"}
+	if !reflect.DeepEqual(rel, want) {
+		t.Errorf("walkFiles = %v, want %v", rel, want)
+	}
+
+	// A subdirectory walk still honors the repository root's .gitignore.
+	files, err = walkFiles(filepath.Join(dir, "logs"), "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Errorf("walkFiles(logs) = %v, want none", files)
+	}
+}
+
+func TestWalkFiles_ExcludeNames(t *testing.T) {
+	dir := t.TempDir()
+	for _, f := range []string{"a.go", "b.pb.go", "gen/c.go", "sub/gen/d.go"} {
+		os.MkdirAll(filepath.Join(dir, filepath.Dir(f)), 0755)
+		os.WriteFile(filepath.Join(dir, f), []byte("x\n"), 0644)
+	}
+	ExcludeNames = []string{"gen", "*.pb.go"}
+	defer func() { ExcludeNames = nil }()
+
+	files, err := walkFiles(dir, "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{filepath.Join(dir, "a.go")}; !reflect.DeepEqual(files, want) {
+		t.Errorf("walkFiles = %v, want %v", files, want)
+	}
+}
+
+func TestIgnoreGlobToRegexp(t *testing.T) {
+	cases := []struct {
+		glob, path string
+		want       bool
+	}{
+		{"*.log", "a.log", true},
+		{"*.log", "dir/a.log", false},
+		{"a/**/b", "a/b", true},
+		{"a/**/b", "a/x/y/b", true},
+		{"doc/*.txt", "doc/x/y.txt", false},
+		{"file[0-9]", "file7", true},
+		{"file[!0-9]", "file7", false},
+	}
+	for _, c := range cases {
+		rule, ok := parseIgnoreRule("/", c.glob)
+		if !ok {
+			t.Fatalf("parseIgnoreRule(%q) failed", c.glob)
+		}
+		if got := rule.re.MatchString(c.path); got != c.want {
+			t.Errorf("%q matches %q = %v, want %v", c.glob, c.path, got, c.want)
+		}
+	}
+}
+
+func TestMapFiles_Order(t *testing.T) {
+	var files []string
+	for i := range 50 {
+		files = append(files, fmt.Sprint(i))
+	}
+	out, err := mapFiles(files, func(f string) (string, error) { return "f" + f, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, o := range out {
+		if o != "f"+files[i] {
+			t.Fatalf("out[%d] = %q, want %q", i, o, "f"+files[i])
+		}
+	}
+
+	_, err = mapFiles(files, func(f string) (int, error) {
+		if f == "7" || f == "30" {
+			return 0, fmt.Errorf("bad %s", f)
+		}
+		return 0, nil
+	})
+	if err == nil || err.Error() != "bad 7" {
+		t.Errorf("err = %v, want bad 7", err)
+	}
+}
@@ -0,0 +1,195 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Budget caps how much output a command prints. Zero fields are unlimited.
+type Budget struct {
+	MaxLines int
+	MaxBytes int
+}
+
+// Limited reports whether any cap is set.
+func (b Budget) Limited() bool {
+	return b.MaxLines > 0 || b.MaxBytes > 0
+}
+
+// Without returns what is left of b once text is printed, keeping at least
+// one line and byte of any cap so a limited budget stays limited.
+func (b Budget) Without(text string) Budget {
+	if b.MaxLines > 0 {
+		b.MaxLines = max(b.MaxLines-len(splitLines(text)), 1)
+	}
+	if b.MaxBytes > 0 {
+		b.MaxBytes = max(b.MaxBytes-len(text), 1)
+	}
+	return b
+}
+
+// fits reports whether lines lines totalling size bytes are within b.
+func (b Budget) fits(lines, size int) bool {
+	return (b.MaxLines <= 0 || lines <= b.MaxLines) && (b.MaxBytes <= 0 || size <= b.MaxBytes)
+}
+
+// rerun returns the flags that would show all of lines lines and size
+// bytes, for the caps that are exceeded.
+func (b Budget) rerun(lines, size int) string {
+	var flags []string
+	if b.MaxLines > 0 && lines > b.MaxLines {
+		flags = append(flags, fmt.Sprintf("--max-lines=%d", lines))
+	}
+	if b.MaxBytes > 0 && size > b.MaxBytes {
+		flags = append(flags, fmt.Sprintf("--max-bytes=%d", size))
+	}
+	if len(flags) == 0 {
+		return "--max-lines=0"
+	}
+	return strings.Join(flags, " ")
+}
+
+// Truncate cuts text at a line boundary so that it fits b, replacing the
+// rest with a "[N more lines omitted, rerun with ...]" marker. Text that
+// fits is returned unchanged.
+func Truncate(text string, b Budget) string {
+	lines := splitLines(text)
+	if b.fits(len(lines), len(text)) {
+		return text
+	}
+	return truncate(lines, strings.HasSuffix(text, "\n"), b, b.rerun(len(lines), len(text)))
+}
+
+// TruncateLines cuts text to at most max lines like Truncate, with a marker
+// naming flag as the option that would show everything.
+func TruncateLines(text string, max int, flag string) string {
+	lines := splitLines(text)
+	if max <= 0 || len(lines) <= max {
+		return text
+	}
+	return truncate(lines, strings.HasSuffix(text, "\n"), Budget{MaxLines: max}, fmt.Sprintf("%s=%d", flag, len(lines)))
+}
+
+// TruncateSections truncates each section to a fair share of b, so one
+// long section cannot crowd out the others. frame is the text printed
+// around the sections (titles, separators); it is charged to the budget
+// up front and never truncated.
+func TruncateSections(sections []string, b Budget, frame string) []string {
+	frameLines, frameBytes := len(splitLines(frame)), len(frame)
+	split := make([][]string, len(sections))
+	lineSizes := make([]int, len(sections))
+	byteSizes := make([]int, len(sections))
+	totalLines, totalBytes := frameLines, frameBytes
+	for i, s := range sections {
+		split[i] = splitLines(s)
+		lineSizes[i], byteSizes[i] = len(split[i]), len(s)
+		totalLines += lineSizes[i]
+		totalBytes += byteSizes[i]
+	}
+	if b.fits(totalLines, totalBytes) {
+		return sections
+	}
+
+	var lineShares, byteShares []int
+	if b.MaxLines > 0 {
+		lineShares = Fair(lineSizes, b.MaxLines-frameLines)
+	}
+	if b.MaxBytes > 0 {
+		byteShares = Fair(byteSizes, b.MaxBytes-frameBytes)
+	}
+	hint := b.rerun(totalLines, totalBytes)
+	out := make([]string, len(sections))
+	for i, s := range sections {
+		var share Budget
+		if lineShares != nil {
+			// Every non-empty section keeps room for at least its marker.
+			share.MaxLines = max(lineShares[i], 1)
+		}
+		if byteShares != nil {
+			share.MaxBytes = max(byteShares[i], 1)
+		}
+		if share.fits(lineSizes[i], byteSizes[i]) {
+			out[i] = s
+			continue
+		}
+		out[i] = truncate(split[i], strings.HasSuffix(s, "\n"), share, hint)
+	}
+	return out
+}
+
+// Fair divides total among sections of the given sizes: sections smaller
+// than an equal share get all they need and what they leave is shared
+// among the rest.
+func Fair(sizes []int, total int) []int {
+	shares := make([]int, len(sizes))
+	var open []int
+	for i, n := range sizes {
+		if n > 0 {
+			open = append(open, i)
+		}
+	}
+	remaining := max(total, 0)
+	for len(open) > 0 {
+		share := remaining / len(open)
+		var rest []int
+		for _, i := range open {
+			if sizes[i] <= share {
+				shares[i] = sizes[i]
+				remaining -= sizes[i]
+			} else {
+				rest = append(rest, i)
+			}
+		}
+		if len(rest) == len(open) {
+			for k, i := range open {
+				shares[i] = share
+				if k < remaining%len(open) {
+					shares[i]++
+				}
+			}
+			break
+		}
+		open = rest
+	}
+	return shares
+}
+
+// truncate keeps as many leading lines as fit b together with the marker.
+func truncate(lines []string, newline bool, b Budget, hint string) string {
+	marker := func(omitted int) string {
+		m := fmt.Sprintf("[%d more lines omitted, rerun with %s]", omitted, hint)
+		if newline {
+			m += "\n"
+		}
+		return m
+	}
+
+	keep := len(lines)
+	if b.MaxLines > 0 {
+		keep = min(keep, b.MaxLines-1)
+	}
+	if b.MaxBytes > 0 {
+		room := b.MaxBytes - len(marker(len(lines)))
+		n, size := 0, 0
+		for n < keep && size+len(lines[n]) <= room {
+			size += len(lines[n])
+			n++
+		}
+		keep = n
+	}
+	keep = max(keep, 0)
+	return strings.Join(lines[:keep], "") + marker(len(lines)-keep)
+}
+
+// splitLines splits text after each newline; a final line without one is
+// kept as is.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
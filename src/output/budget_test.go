@@ -0,0 +1,120 @@
+package output
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func numbered(n int) string {
+	var sb strings.Builder
+	for i := 1; i <= n; i++ {
+		sb.WriteString(strings.Repeat("x", i%10) + "\n")
+	}
+	return sb.String()
+}
+
+func TestTruncate_Fits(t *testing.T) {
+	text := numbered(5)
+	if got := Truncate(text, Budget{MaxLines: 5}); got != text {
+		t.Errorf("text within budget changed:\n%s", got)
+	}
+	if got := Truncate(text, Budget{}); got != text {
+		t.Errorf("unlimited budget changed text:\n%s", got)
+	}
+}
+
+func TestTruncate_Lines(t *testing.T) {
+	got := Truncate("a\nb\nc\nd\ne\n", Budget{MaxLines: 3})
+	want := "a\nb\n[3 more lines omitted, rerun with --max-lines=5]\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestTruncate_Bytes(t *testing.T) {
+	text := numbered(40)
+	got := Truncate(text, Budget{MaxBytes: 100})
+	if len(got) > 100 {
+		t.Errorf("output is %d bytes, want <= 100:\n%s", len(got), got)
+	}
+	if !strings.HasPrefix(text, got[:strings.LastIndex(got, "[")]) {
+		t.Errorf("kept lines are not a prefix of the input:\n%s", got)
+	}
+	if !strings.Contains(got, "rerun with --max-bytes=") {
+		t.Errorf("missing bytes hint:\n%s", got)
+	}
+}
+
+func TestTruncate_NoTrailingNewline(t *testing.T) {
+	got := Truncate("a\nb\nc", Budget{MaxLines: 2})
+	want := "a\n[2 more lines omitted, rerun with --max-lines=3]"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestBudgetWithout(t *testing.T) {
+	if got := (Budget{MaxLines: 5, MaxBytes: 100}).Without("ab\ncd\n"); got != (Budget{MaxLines: 3, MaxBytes: 94}) {
+		t.Errorf("Without = %+v", got)
+	}
+	if got := (Budget{MaxLines: 1}).Without("a\nb\n"); got != (Budget{MaxLines: 1}) {
+		t.Errorf("Without past the cap = %+v, want MaxLines 1", got)
+	}
+	if got := (Budget{}).Without("a\n"); got.Limited() {
+		t.Errorf("Without made an unlimited budget limited: %+v", got)
+	}
+}
+
+func TestTruncateLines(t *testing.T) {
+	got := TruncateLines("a\nb\nc\nd\n", 3, "--patch-lines")
+	want := "a\nb\n[2 more lines omitted, rerun with --patch-lines=4]\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := TruncateLines("a\nb\n", 0, "--patch-lines"); got != "a\nb\n" {
+		t.Errorf("unlimited changed text: %q", got)
+	}
+}
+
+func TestFair(t *testing.T) {
+	tests := []struct {
+		sizes []int
+		total int
+		want  []int
+	}{
+		{[]int{2, 10, 10}, 12, []int{2, 5, 5}},
+		{[]int{1, 2, 3}, 100, []int{1, 2, 3}},
+		{[]int{10, 10, 10}, 10, []int{4, 3, 3}},
+		{[]int{0, 5}, 3, []int{0, 3}},
+		{[]int{5, 5}, -1, []int{0, 0}},
+	}
+	for _, tt := range tests {
+		if got := Fair(tt.sizes, tt.total); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Fair(%v, %d) = %v, want %v", tt.sizes, tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestTruncateSections(t *testing.T) {
+	sections := []string{"a\n", numbered(20), numbered(20)}
+	got := TruncateSections(sections, Budget{MaxLines: 12}, "h\nh\n")
+
+	if got[0] != "a\n" {
+		t.Errorf("short section changed: %q", got[0])
+	}
+	lines := 2
+	for i, s := range got {
+		lines += len(splitLines(s))
+		if i > 0 && !strings.Contains(s, "omitted, rerun with --max-lines=43]") {
+			t.Errorf("section %d missing marker:\n%s", i, s)
+		}
+	}
+	if lines != 12 {
+		t.Errorf("total lines = %d, want 12", lines)
+	}
+
+	if got := TruncateSections(sections, Budget{MaxLines: 100}, ""); !reflect.DeepEqual(got, sections) {
+		t.Errorf("sections within budget changed")
+	}
+}
@@ -2,6 +2,7 @@ package runner
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
@@ -57,3 +58,22 @@ func Exec(args []string) error {
 	}
 	return syscall.Exec(path, args, os.Environ())
 }
+
+// ExecTo replaces the current process with the command when w is
+// os.Stdout, so output streams to the terminal and pager as usual.
+// Otherwise it runs the command and copies its output to w, failing with
+// its stderr on a non-zero exit.
+func ExecTo(w io.Writer, args []string) error {
+	if w == os.Stdout {
+		return Exec(args)
+	}
+	r, err := run(args)
+	if err != nil {
+		return err
+	}
+	io.WriteString(w, r.Stdout)
+	if r.ExitCode != 0 {
+		return fmt.Errorf("command %v exited with code %d: %s", args, r.ExitCode, strings.TrimSpace(r.Stderr))
+	}
+	return nil
+}
@@ -31,3 +31,16 @@ func TestRunNoCheck_NonZeroExit(t *testing.T) {
 		t.Error("expected non-zero exit code")
 	}
 }
+
+func TestExecTo_Captures(t *testing.T) {
+	var buf strings.Builder
+	if err := ExecTo(&buf, []string{"echo", "hello"}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "hello\n" {
+		t.Errorf("output = %q", buf.String())
+	}
+	if err := ExecTo(&buf, []string{"sh", "-c", "echo oops >&2; exit 3"}); err == nil || !strings.Contains(err.Error(), "oops") {
+		t.Errorf("err = %v, want stderr in error", err)
+	}
+}
@@ -0,0 +1,177 @@
+package tickets
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Backend kinds accepted by NewBackend and FindBackends. An empty kind
+// auto-detects from the path.
+const (
+	KindMarkdown = "tickets"
+	KindBeads    = "beads"
+)
+
+// Backend is a source of tickets: a .tickets/ directory of markdown files or
+// a beads issue export.
+type Backend interface {
+	// Name labels the backend's tickets when several backends are merged.
+	Name() string
+	Load() ([]Ticket, error)
+}
+
+// MarkdownBackend loads .md tickets with YAML frontmatter from Dir.
+type MarkdownBackend struct {
+	Dir string
+}
+
+func (b MarkdownBackend) Name() string { return SourceLabel(b.Dir) }
+
+func (b MarkdownBackend) Load() ([]Ticket, error) { return LoadTickets(b.Dir) }
+
+// BeadsBackend loads a beads issue list, either the JSON array printed by
+// `bd list --json` or the JSONL file kept in .beads/issues.jsonl.
+type BeadsBackend struct {
+	Path string
+}
+
+func (b BeadsBackend) Name() string { return SourceLabel(filepath.Dir(b.Path)) }
+
+func (b BeadsBackend) Load() ([]Ticket, error) { return LoadBeads(b.Path) }
+
+type beadsIssue struct {
+	ID           string            `json:"id"`
+	Title        string            `json:"title"`
+	IssueType    string            `json:"issue_type"`
+	Status       string            `json:"status"`
+	Priority     int               `json:"priority"`
+	Labels       []string          `json:"labels"`
+	Dependencies []beadsDependency `json:"dependencies"`
+}
+
+type beadsDependency struct {
+	Type        string `json:"type"`
+	DependsOnID string `json:"depends_on_id"`
+}
+
+func (bi beadsIssue) ticket() Ticket {
+	tk := Ticket{
+		ID:       bi.ID,
+		Title:    bi.Title,
+		Type:     bi.IssueType,
+		Status:   bi.Status,
+		Priority: bi.Priority,
+		Tags:     bi.Labels,
+	}
+	for _, d := range bi.Dependencies {
+		if d.Type == "parent-child" {
+			tk.Parent = d.DependsOnID
+			break
+		}
+	}
+	return tk
+}
+
+// LoadBeads reads beads issues from a JSON array or JSONL file.
+func LoadBeads(path string) ([]Ticket, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading beads file %s: %w", path, err)
+	}
+
+	var issues []beadsIssue
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+		if err := json.Unmarshal(data, &issues); err != nil {
+			return nil, fmt.Errorf("parsing beads JSON %s: %w", path, err)
+		}
+	} else {
+		scanner := bufio.NewScanner(strings.NewReader(string(data)))
+		scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			var bi beadsIssue
+			if err := json.Unmarshal([]byte(line), &bi); err != nil {
+				continue
+			}
+			issues = append(issues, bi)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	tickets := make([]Ticket, 0, len(issues))
+	for _, bi := range issues {
+		tickets = append(tickets, bi.ticket())
+	}
+	return tickets, nil
+}
+
+// NewBackend picks a backend for path. With an empty kind, plain files and
+// .beads/ directories are beads; other directories are markdown tickets.
+func NewBackend(path, kind string) (Backend, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("tickets source %s not found", path)
+	}
+
+	if kind == "" {
+		switch {
+		case !info.IsDir():
+			kind = KindBeads
+		case filepath.Base(path) == defaultBeadsDir:
+			kind = KindBeads
+		default:
+			kind = KindMarkdown
+		}
+	}
+
+	switch kind {
+	case KindMarkdown:
+		if !info.IsDir() {
+			return nil, fmt.Errorf("tickets dir %s is not a directory", path)
+		}
+		return MarkdownBackend{Dir: path}, nil
+	case KindBeads:
+		if info.IsDir() {
+			path = filepath.Join(path, "issues.jsonl")
+		}
+		return BeadsBackend{Path: path}, nil
+	}
+	return nil, fmt.Errorf("unknown ticket backend %q (want %s or %s)", kind, KindMarkdown, KindBeads)
+}
+
+// LoadBackends loads and merges tickets from several backends. With more
+// than one backend, IDs and parents are qualified as "label:id", with labels
+// from backendLabels, so tickets from different sources cannot collide.
+func LoadBackends(backends []Backend) ([]Ticket, error) {
+	if len(backends) == 1 {
+		return backends[0].Load()
+	}
+
+	labels := backendLabels(backends)
+	var all []Ticket
+	for i, b := range backends {
+		items, err := b.Load()
+		if err != nil {
+			return nil, err
+		}
+		label := labels[i]
+		for _, tk := range items {
+			tk.Source = label
+			tk.ID = label + ":" + tk.ID
+			if tk.Parent != "" {
+				tk.Parent = label + ":" + tk.Parent
+			}
+			all = append(all, tk)
+		}
+	}
+	return all, nil
+}
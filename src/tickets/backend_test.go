@@ -0,0 +1,119 @@
+package tickets
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadBeads_JSONArray(t *testing.T) {
+	items, err := LoadBeads("../../testdata/beads/sample_list.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 5 {
+		t.Fatalf("expected 5 tickets, got %d", len(items))
+	}
+	byID := make(map[string]Ticket)
+	for _, tk := range items {
+		byID[tk.ID] = tk
+	}
+	if tk := byID["T3"]; tk.Parent != "E2" || tk.Type != "task" || tk.Priority != 2 {
+		t.Errorf("T3 = %+v, want parent E2, type task, priority 2", tk)
+	}
+	if tk := byID["E1"]; !tk.IsEpic() || tk.Parent != "" {
+		t.Errorf("E1 = %+v, want top-level epic", tk)
+	}
+}
+
+func TestLoadBeads_JSONL(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "issues.jsonl")
+	os.WriteFile(path, []byte(`{"id":"B1","title":"Epic","issue_type":"epic","status":"open","priority":1}
+{"id":"B2","title":"Blocked by","issue_type":"task","status":"open","priority":2,"dependencies":[{"type":"blocks","depends_on_id":"B9"},{"type":"parent-child","depends_on_id":"B1"}]}
+`), 0644)
+
+	items, err := LoadBeads(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 tickets, got %d", len(items))
+	}
+	if items[1].Parent != "B1" {
+		t.Errorf("parent = %q, want B1 (non parent-child deps ignored)", items[1].Parent)
+	}
+}
+
+func TestNewBackend_AutoDetect(t *testing.T) {
+	dir := t.TempDir()
+	beads := filepath.Join(dir, ".beads")
+	os.Mkdir(beads, 0755)
+
+	cases := []struct {
+		path  string
+		beads bool
+	}{
+		{"../../testdata/tickets", false},
+		{"../../testdata/beads/sample_list.json", true},
+		{beads, true},
+	}
+	for _, c := range cases {
+		b, err := NewBackend(c.path, "")
+		if err != nil {
+			t.Fatalf("%s: %v", c.path, err)
+		}
+		if _, isBeads := b.(BeadsBackend); isBeads != c.beads {
+			t.Errorf("%s: got %T, want beads=%v", c.path, b, c.beads)
+		}
+	}
+	if b, _ := NewBackend(beads, ""); b.(BeadsBackend).Path != filepath.Join(beads, "issues.jsonl") {
+		t.Errorf("beads dir should resolve to issues.jsonl, got %v", b)
+	}
+}
+
+func TestNewBackend_KindMismatch(t *testing.T) {
+	if _, err := NewBackend("../../testdata/beads/sample_list.json", KindMarkdown); err == nil {
+		t.Error("expected error for markdown backend on a file")
+	}
+	if _, err := NewBackend("../../testdata/tickets", "jira"); err == nil {
+		t.Error("expected error for unknown backend")
+	}
+}
+
+func TestBuildStatusReport_Beads(t *testing.T) {
+	items, err := LoadItems([]string{"../../testdata/beads/sample_list.json"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := BuildStatusReport(items, "2026-02-28")
+	if !strings.Contains(report, "Epic One") || !strings.Contains(report, "Sub Epic") {
+		t.Errorf("beads report missing epics:\n%s", report)
+	}
+	if strings.Contains(report, "[Orphaned]") {
+		t.Errorf("beads tickets should all be parented:\n%s", report)
+	}
+}
+
+func TestLoadBackends_Mixed(t *testing.T) {
+	items, err := LoadBackends([]Backend{
+		MarkdownBackend{Dir: "../../testdata/tickets"},
+		BeadsBackend{Path: "../../testdata/beads/sample_list.json"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 12 {
+		t.Fatalf("expected 12 tickets, got %d", len(items))
+	}
+	var sawBeads bool
+	for _, tk := range items {
+		if tk.ID == "beads:T3" && tk.Parent == "beads:E2" {
+			sawBeads = true
+		}
+	}
+	if !sawBeads {
+		t.Errorf("missing prefixed beads ticket in %v", items)
+	}
+}
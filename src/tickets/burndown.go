@@ -0,0 +1,215 @@
+package tickets
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BurndownPoint is the ticket count at the end of one interval.
+type BurndownPoint struct {
+	Date   time.Time
+	Open   int
+	Closed int
+}
+
+const burndownWidth = 40
+
+// intervalStart truncates t to the start of its day, or of its ISO week
+// (Monday) when weekly is set.
+func intervalStart(t time.Time, weekly bool) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if weekly {
+		offset := (int(day.Weekday()) + 6) % 7
+		day = day.AddDate(0, 0, -offset)
+	}
+	return day
+}
+
+// Burndown replays events and samples open/closed counts at the end of each
+// day (or week) from since through until. include decides which tickets are
+// counted given their id and type; nil counts every non-epic ticket.
+func Burndown(events []Event, since, until time.Time, weekly bool, include func(id, typ string) bool) []BurndownPoint {
+	if len(events) == 0 {
+		return nil
+	}
+	sorted := append([]Event(nil), events...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+	if include == nil {
+		include = func(id, typ string) bool { return typ != "epic" }
+	}
+	if since.IsZero() {
+		since = sorted[0].Time
+	}
+
+	status := make(map[string]string)
+	types := make(map[string]string)
+	apply := func(ev Event) {
+		switch ev.Kind {
+		case EventCreated:
+			types[ev.ID] = ev.Type
+			status[ev.ID] = ev.To
+		case EventDeleted:
+			delete(status, ev.ID)
+		default:
+			status[ev.ID] = ev.To
+		}
+	}
+
+	var points []BurndownPoint
+	next := 0
+	for start := intervalStart(since, weekly); !start.After(until); {
+		end := start.AddDate(0, 0, 1)
+		if weekly {
+			end = start.AddDate(0, 0, 7)
+		}
+		for next < len(sorted) && sorted[next].Time.Before(end) {
+			apply(sorted[next])
+			next++
+		}
+
+		p := BurndownPoint{Date: start}
+		for id, st := range status {
+			if !include(id, types[id]) {
+				continue
+			}
+			if st == "closed" {
+				p.Closed++
+			} else {
+				p.Open++
+			}
+		}
+		points = append(points, p)
+		start = end
+	}
+	return points
+}
+
+// RenderBurndown draws one bar per interval: '#' for open tickets and '='
+// for closed ones, scaled to a fixed width.
+func RenderBurndown(title string, points []BurndownPoint, weekly bool) string {
+	interval := "daily"
+	if weekly {
+		interval = "weekly"
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Burndown — %s  (%s; # open, = closed)\n\n", title, interval)
+	if len(points) == 0 {
+		sb.WriteString("(no ticket history)\n")
+		return sb.String()
+	}
+
+	maxTotal := 0
+	for _, p := range points {
+		if t := p.Open + p.Closed; t > maxTotal {
+			maxTotal = t
+		}
+	}
+	scale := func(n int) int {
+		if maxTotal <= burndownWidth {
+			return n
+		}
+		return (n*burndownWidth + maxTotal/2) / maxTotal
+	}
+
+	for _, p := range points {
+		bar := strings.Repeat("#", scale(p.Open)) + strings.Repeat("=", scale(p.Closed))
+		fmt.Fprintf(&sb, "%s  open %4d  closed %4d  %s\n", p.Date.Format("2006-01-02"), p.Open, p.Closed, bar)
+	}
+	return sb.String()
+}
+
+// ParseSince accepts a YYYY-MM-DD date or a relative "Nd"/"Nw" window
+// counted back from now. An empty string means no lower bound.
+func ParseSince(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	if len(s) > 1 {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err == nil && n >= 0 {
+			switch s[len(s)-1] {
+			case 'd':
+				return now.AddDate(0, 0, -n), nil
+			case 'w':
+				return now.AddDate(0, 0, -7*n), nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q (want YYYY-MM-DD, Nd or Nw)", s)
+}
+
+// epicMembers returns the IDs of all non-epic descendants of epic.
+func epicMembers(items []Ticket, epic string) map[string]bool {
+	children := make(map[string][]Ticket)
+	for _, it := range items {
+		if it.Parent != "" {
+			children[it.Parent] = append(children[it.Parent], it)
+		}
+	}
+	members := make(map[string]bool)
+	stack := []string{epic}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, child := range children[id] {
+			if child.IsEpic() {
+				stack = append(stack, child.ID)
+			} else {
+				members[child.ID] = true
+			}
+		}
+	}
+	return members
+}
+
+// RunTicketBurndown prints a burndown chart for all tickets, or for the
+// descendants of epic.
+func RunTicketBurndown(w io.Writer, paths []string, kind, since, epic string, weekly bool) error {
+	now := time.Now()
+	from, err := ParseSince(since, now)
+	if err != nil {
+		return err
+	}
+
+	backends, err := FindBackends(paths, kind)
+	if err != nil {
+		return err
+	}
+	events, err := LoadHistory(backends)
+	if err != nil {
+		return err
+	}
+	items, err := LoadBackends(backends)
+	if err != nil {
+		return err
+	}
+
+	title := "all tickets"
+	var include func(id, typ string) bool
+	if epic != "" {
+		var found bool
+		for _, it := range items {
+			if it.ID == epic {
+				title = fmt.Sprintf("%s %s", it.ID, it.Title)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("epic %s not found", epic)
+		}
+		members := epicMembers(items, epic)
+		include = func(id, typ string) bool { return members[id] }
+	}
+
+	fmt.Fprint(w, RenderBurndown(title, Burndown(events, from, now, weekly, include), weekly))
+	return nil
+}
@@ -0,0 +1,213 @@
+package tickets
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"repotools/src/git"
+)
+
+// EnvTicketsDir overrides ticket directory discovery. It may hold several
+// directories separated by the OS path list separator.
+const EnvTicketsDir = "REPOTOOLS_TICKETS_DIR"
+
+// ConfigTicketsDir is the git config key (repeatable) naming ticket
+// directories relative to the repository root.
+const ConfigTicketsDir = "repotools.ticketsDir"
+
+// ConfigDirs are ticket directories relative to the repository root, as set
+// by the config file. They are used when git config names none.
+var ConfigDirs []string
+
+const (
+	defaultTicketsDir = ".tickets"
+	defaultBeadsDir   = ".beads"
+)
+
+// FindBackends resolves the ticket sources to load. Explicit paths win, then
+// $REPOTOOLS_TICKETS_DIR, then git config repotools.ticketsDir, then
+// ConfigDirs, and finally the nearest .tickets/ (or .beads/) found walking
+// up from the working directory to the git root. kind forces a backend; empty auto-detects.
+func FindBackends(explicit []string, kind string) ([]Backend, error) {
+	paths, err := findTicketPaths(explicit, kind)
+	if err != nil {
+		return nil, err
+	}
+	var backends []Backend
+	for _, p := range paths {
+		b, err := NewBackend(p, kind)
+		if err != nil {
+			return nil, err
+		}
+		backends = append(backends, b)
+	}
+	return backends, nil
+}
+
+func findTicketPaths(explicit []string, kind string) ([]string, error) {
+	if len(explicit) > 0 {
+		return checkPaths(explicit)
+	}
+	if env := os.Getenv(EnvTicketsDir); env != "" {
+		return checkPaths(filepath.SplitList(env))
+	}
+
+	root, _ := git.Toplevel()
+	if root != "" {
+		vals := git.ConfigAll(ConfigTicketsDir)
+		if len(vals) == 0 {
+			vals = ConfigDirs
+		}
+		if len(vals) > 0 {
+			var paths []string
+			for _, v := range vals {
+				if !filepath.IsAbs(v) {
+					v = filepath.Join(root, v)
+				}
+				paths = append(paths, v)
+			}
+			return checkPaths(paths)
+		}
+	}
+
+	var names []string
+	switch kind {
+	case KindMarkdown:
+		names = []string{defaultTicketsDir}
+	case KindBeads:
+		names = []string{defaultBeadsDir}
+	default:
+		names = []string{defaultTicketsDir, defaultBeadsDir}
+	}
+	p, err := findUpward(names, root)
+	if err != nil {
+		return nil, err
+	}
+	return []string{p}, nil
+}
+
+// findUpward looks for the first of names in the working directory and each
+// parent up to and including root. With no root only the working directory is
+// checked.
+func findUpward(names []string, root string) (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	if root != "" {
+		if r, err := filepath.EvalSymlinks(root); err == nil {
+			root = r
+		}
+		if c, err := filepath.EvalSymlinks(cwd); err == nil {
+			cwd = c
+		}
+	}
+
+	dir := cwd
+	for {
+		for _, name := range names {
+			candidate := filepath.Join(dir, name)
+			if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+				if rel, err := filepath.Rel(cwd, candidate); err == nil {
+					return rel, nil
+				}
+				return candidate, nil
+			}
+		}
+		parent := filepath.Dir(dir)
+		if root == "" || dir == root || parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return "", fmt.Errorf("no %s/ directory found", strings.Join(names, "/ or "))
+}
+
+func checkPaths(paths []string) ([]string, error) {
+	var out []string
+	for _, p := range paths {
+		if p == "" {
+			continue
+		}
+		if _, err := os.Stat(p); err != nil {
+			return nil, fmt.Errorf("tickets source %s not found", p)
+		}
+		out = append(out, p)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no tickets directory configured")
+	}
+	return out, nil
+}
+
+// SourceLabel names a ticket directory for prefixing merged reports:
+// pkg/foo/.tickets (or pkg/foo/.beads) becomes "foo", anything else uses its
+// base name.
+func SourceLabel(dir string) string {
+	return sourceLabel(dir, 1)
+}
+
+// sourceLabel is SourceLabel with the last depth directories, joined by "/".
+func sourceLabel(dir string, depth int) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		abs = dir
+	}
+	if base := filepath.Base(abs); base == defaultTicketsDir || base == defaultBeadsDir {
+		abs = filepath.Dir(abs)
+	}
+	var parts []string
+	for ; depth > 0; depth-- {
+		base := filepath.Base(abs)
+		if base == string(filepath.Separator) || base == "." {
+			break
+		}
+		parts = append([]string{strings.TrimPrefix(base, ".")}, parts...)
+		abs = filepath.Dir(abs)
+	}
+	return strings.Join(parts, "/")
+}
+
+// backendLabels names each backend for qualifying merged IDs. Backends with
+// the same name get parent directories added until the names differ, so
+// pkg/a/.tickets and other/a/.tickets become "pkg/a" and "other/a".
+func backendLabels(backends []Backend) []string {
+	labels := make([]string, len(backends))
+	for i, b := range backends {
+		labels[i] = b.Name()
+	}
+	for depth := 2; ; depth++ {
+		count := make(map[string]int)
+		for _, l := range labels {
+			count[l]++
+		}
+		grew := false
+		for i, b := range backends {
+			dir := backendDir(b)
+			if count[labels[i]] < 2 || dir == "" {
+				continue
+			}
+			if l := sourceLabel(dir, depth); l != labels[i] {
+				labels[i], grew = l, true
+			}
+		}
+		// Stop once unique, or when only the same directory repeats.
+		if !grew {
+			return labels
+		}
+	}
+}
+
+// backendDir is the directory a backend's name comes from, or "" for
+// backends not read from disk.
+func backendDir(b Backend) string {
+	switch b := b.(type) {
+	case MarkdownBackend:
+		return b.Dir
+	case BeadsBackend:
+		return filepath.Dir(b.Path)
+	}
+	return ""
+}
@@ -0,0 +1,183 @@
+package tickets
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func setupTicketRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if out, err := exec.Command("git", "init", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %s: %v", out, err)
+	}
+	os.MkdirAll(filepath.Join(dir, ".tickets"), 0755)
+	os.MkdirAll(filepath.Join(dir, "pkg", "sub"), 0755)
+	return dir
+}
+
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	oldDir, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(oldDir) })
+}
+
+// findDirs runs FindBackends for markdown tickets and returns their dirs.
+func findDirs(explicit []string) ([]string, error) {
+	backends, err := FindBackends(explicit, KindMarkdown)
+	if err != nil {
+		return nil, err
+	}
+	dirs := make([]string, len(backends))
+	for i, b := range backends {
+		dirs[i] = b.(MarkdownBackend).Dir
+	}
+	return dirs, nil
+}
+
+func TestFindBackends_Upward(t *testing.T) {
+	dir := setupTicketRepo(t)
+	chdir(t, filepath.Join(dir, "pkg", "sub"))
+	t.Setenv(EnvTicketsDir, "")
+
+	dirs, err := findDirs(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dirs) != 1 || dirs[0] != filepath.Join("..", "..", ".tickets") {
+		t.Errorf("got %v, want [../../.tickets]", dirs)
+	}
+}
+
+func TestFindBackends_StopsAtGitRoot(t *testing.T) {
+	outer := t.TempDir()
+	os.Mkdir(filepath.Join(outer, ".tickets"), 0755)
+	repo := filepath.Join(outer, "repo")
+	if out, err := exec.Command("git", "init", repo).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %s: %v", out, err)
+	}
+	chdir(t, repo)
+	t.Setenv(EnvTicketsDir, "")
+
+	if _, err := findDirs(nil); err == nil {
+		t.Error("expected error: .tickets above the git root should not be found")
+	}
+}
+
+func TestFindBackends_Env(t *testing.T) {
+	dir := setupTicketRepo(t)
+	chdir(t, dir)
+	a := filepath.Join(dir, "a")
+	b := filepath.Join(dir, "b")
+	os.Mkdir(a, 0755)
+	os.Mkdir(b, 0755)
+	t.Setenv(EnvTicketsDir, a+string(os.PathListSeparator)+b)
+
+	dirs, err := findDirs(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dirs) != 2 || dirs[0] != a || dirs[1] != b {
+		t.Errorf("got %v, want [%s %s]", dirs, a, b)
+	}
+}
+
+func TestFindBackends_GitConfig(t *testing.T) {
+	dir := setupTicketRepo(t)
+	os.MkdirAll(filepath.Join(dir, "pkg", ".tickets"), 0755)
+	exec.Command("git", "-C", dir, "config", "--add", ConfigTicketsDir, "pkg/.tickets").Run()
+	chdir(t, filepath.Join(dir, "pkg", "sub"))
+	t.Setenv(EnvTicketsDir, "")
+
+	dirs, err := findDirs(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dirs) != 1 || !strings.HasSuffix(dirs[0], filepath.Join("pkg", ".tickets")) {
+		t.Errorf("got %v, want pkg/.tickets", dirs)
+	}
+}
+
+func TestFindBackends_ExplicitMissing(t *testing.T) {
+	if _, err := findDirs([]string{"/nonexistent/tickets"}); err == nil {
+		t.Fatal("expected error for missing dir")
+	}
+}
+
+func TestSourceLabel(t *testing.T) {
+	cases := map[string]string{
+		"pkg/foo/.tickets": "foo",
+		"/work/tickets":    "tickets",
+	}
+	for in, want := range cases {
+		if got := SourceLabel(in); got != want {
+			t.Errorf("SourceLabel(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSourceLabel_Depth(t *testing.T) {
+	if got := sourceLabel("/work/pkg/a/.tickets", 2); got != "pkg/a" {
+		t.Errorf("got %q, want pkg/a", got)
+	}
+	if got := sourceLabel("/a/.beads", 5); got != "a" {
+		t.Errorf("got %q, want a", got)
+	}
+}
+
+func TestLoadBackends_Merged(t *testing.T) {
+	root := t.TempDir()
+	other := filepath.Join(root, "other", ".tickets")
+	os.MkdirAll(other, 0755)
+	os.WriteFile(filepath.Join(other, "X1.md"), []byte("---\nid: X1\nstatus: open\ntype: task\nparent: E1\n---\n# Other Task\n"), 0644)
+
+	items, err := LoadBackends([]Backend{MarkdownBackend{Dir: "../../testdata/tickets"}, MarkdownBackend{Dir: other}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 8 {
+		t.Fatalf("expected 8 tickets, got %d", len(items))
+	}
+	var found bool
+	for _, tk := range items {
+		if tk.ID == "other:X1" {
+			found = true
+			if tk.Parent != "other:E1" || tk.Source != "other" {
+				t.Errorf("got parent %q source %q, want other:E1 / other", tk.Parent, tk.Source)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("missing prefixed ticket other:X1 in %v", items)
+	}
+
+	report := BuildStatusReport(items, "2026-02-28")
+	if !strings.Contains(report, "tickets:E1") {
+		t.Errorf("missing source prefix in:\n%s", report)
+	}
+}
+
+func TestLoadBackends_SameLabel(t *testing.T) {
+	root := t.TempDir()
+	var backends []Backend
+	for _, parent := range []string{"pkg", "other"} {
+		dir := filepath.Join(root, parent, "a", ".tickets")
+		os.MkdirAll(dir, 0755)
+		os.WriteFile(filepath.Join(dir, "T1.md"), []byte("---\nid: T1\nstatus: open\ntype: task\n---\n# Task\n"), 0644)
+		backends = append(backends, MarkdownBackend{Dir: dir})
+	}
+
+	items, err := LoadBackends(backends)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].ID != "pkg/a:T1" || items[1].ID != "other/a:T1" {
+		t.Errorf("got %v, want IDs pkg/a:T1 and other/a:T1", items)
+	}
+}
@@ -0,0 +1,376 @@
+package tickets
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"repotools/src/runner"
+)
+
+// Event kinds recorded in ticket history.
+const (
+	EventCreated = "created"
+	EventStatus  = "status"
+	EventDeleted = "deleted"
+)
+
+// Event is one change to a ticket reconstructed from git history.
+type Event struct {
+	Time   time.Time
+	Commit string
+	ID     string
+	Kind   string
+	From   string
+	To     string
+	// Type and Title are only known for created events.
+	Type  string
+	Title string
+}
+
+// HistoryBackend is implemented by backends whose files are tracked in git
+// and can replay their changes.
+type HistoryBackend interface {
+	Backend
+	History() ([]Event, error)
+}
+
+// gitLogPatch runs git log oldest-first with zero-context patches for path,
+// prefixing each commit with a NUL-delimited "sha date" header.
+func gitLogPatch(path string) (string, error) {
+	r, err := runner.Run([]string{
+		"git", "log", "--reverse", "-p", "-U0", "--no-color", "--no-renames",
+		"--format=%x00%H %cI", "--", path,
+	})
+	if err != nil {
+		return "", err
+	}
+	return r.Stdout, nil
+}
+
+type logCommit struct {
+	sha   string
+	time  time.Time
+	patch string
+}
+
+func splitLogCommits(out string) []logCommit {
+	var commits []logCommit
+	for _, chunk := range strings.Split(out, "\x00") {
+		if strings.TrimSpace(chunk) == "" {
+			continue
+		}
+		header, patch, _ := strings.Cut(chunk, "\n")
+		sha, date, _ := strings.Cut(strings.TrimSpace(header), " ")
+		ts, err := time.Parse(time.RFC3339, date)
+		if err != nil {
+			continue
+		}
+		if len(sha) > 7 {
+			sha = sha[:7]
+		}
+		commits = append(commits, logCommit{sha: sha, time: ts, patch: patch})
+	}
+	return commits
+}
+
+func (b MarkdownBackend) History() ([]Event, error) {
+	out, err := gitLogPatch(b.Dir)
+	if err != nil {
+		return nil, err
+	}
+	return parseMarkdownLog(out), nil
+}
+
+// parseMarkdownLog turns `git log -p -U0` output for a tickets directory into
+// events, reading status changes from the frontmatter lines of each patch.
+// A file's id: line usually shows up only in the patch creating it, so the
+// id is remembered per path for the file's later events.
+func parseMarkdownLog(out string) []Event {
+	var events []Event
+	ids := make(map[string]string)
+	for _, c := range splitLogCommits(out) {
+		var file *fileChange
+		var name string
+		flush := func() {
+			if file != nil {
+				ids[name] = file.id
+				events = append(events, file.events(c)...)
+			}
+			file = nil
+		}
+		for _, line := range strings.Split(c.patch, "\n") {
+			switch {
+			case strings.HasPrefix(line, "diff --git "):
+				flush()
+				fields := strings.Fields(line)
+				name = strings.TrimPrefix(fields[len(fields)-1], "b/")
+				if !strings.HasSuffix(name, ".md") {
+					continue
+				}
+				id := ids[name]
+				if id == "" {
+					id = strings.TrimSuffix(filepath.Base(name), ".md")
+				}
+				file = &fileChange{id: id}
+			case file == nil:
+			case strings.HasPrefix(line, "new file mode"):
+				file.created = true
+			case strings.HasPrefix(line, "deleted file mode"):
+				file.deleted = true
+			case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			case strings.HasPrefix(line, "+"), strings.HasPrefix(line, "-"):
+				file.line(line[0], line[1:])
+			}
+		}
+		flush()
+	}
+	return events
+}
+
+type fileChange struct {
+	id               string
+	created, deleted bool
+	oldStatus        string
+	newStatus        string
+	typ, title       string
+}
+
+func (f *fileChange) line(sign byte, text string) {
+	if f.title == "" && sign == '+' && strings.HasPrefix(text, "# ") {
+		f.title = strings.TrimPrefix(text, "# ")
+		return
+	}
+	key, val, ok := parseYAMLLine(text)
+	if !ok {
+		return
+	}
+	switch {
+	case key == "status" && sign == '+':
+		f.newStatus = val
+	case key == "status":
+		f.oldStatus = val
+	case key == "id" && sign == '+':
+		f.id = val
+	case key == "type" && sign == '+':
+		f.typ = val
+	}
+}
+
+func (f *fileChange) events(c logCommit) []Event {
+	ev := Event{Time: c.time, Commit: c.sha, ID: f.id}
+	switch {
+	case f.created:
+		ev.Kind, ev.To, ev.Type, ev.Title = EventCreated, f.newStatus, f.typ, f.title
+	case f.deleted:
+		ev.Kind, ev.From = EventDeleted, f.oldStatus
+	case f.newStatus != "" && f.newStatus != f.oldStatus:
+		ev.Kind, ev.From, ev.To = EventStatus, f.oldStatus, f.newStatus
+	default:
+		return nil
+	}
+	return []Event{ev}
+}
+
+func (b BeadsBackend) History() ([]Event, error) {
+	out, err := gitLogPatch(b.Path)
+	if err != nil {
+		return nil, err
+	}
+	return parseBeadsLog(out), nil
+}
+
+// parseBeadsLog turns `git log -p -U0` output for a beads JSONL file into
+// events by pairing removed and added issue lines with the same id.
+func parseBeadsLog(out string) []Event {
+	var events []Event
+	for _, c := range splitLogCommits(out) {
+		removed := make(map[string]beadsIssue)
+		added := make(map[string]beadsIssue)
+		var order []string
+		for _, line := range strings.Split(c.patch, "\n") {
+			if len(line) < 2 || (line[0] != '+' && line[0] != '-') || line[1] != '{' {
+				continue
+			}
+			var bi beadsIssue
+			if err := json.Unmarshal([]byte(line[1:]), &bi); err != nil || bi.ID == "" {
+				continue
+			}
+			if _, seen := removed[bi.ID]; !seen {
+				if _, seen := added[bi.ID]; !seen {
+					order = append(order, bi.ID)
+				}
+			}
+			if line[0] == '+' {
+				added[bi.ID] = bi
+			} else {
+				removed[bi.ID] = bi
+			}
+		}
+
+		for _, id := range order {
+			ev := Event{Time: c.time, Commit: c.sha, ID: id}
+			old, hadOld := removed[id]
+			cur, hasNew := added[id]
+			switch {
+			case !hadOld:
+				ev.Kind, ev.To, ev.Type, ev.Title = EventCreated, cur.Status, cur.IssueType, cur.Title
+			case !hasNew:
+				ev.Kind, ev.From = EventDeleted, old.Status
+			case old.Status != cur.Status:
+				ev.Kind, ev.From, ev.To = EventStatus, old.Status, cur.Status
+			default:
+				continue
+			}
+			events = append(events, ev)
+		}
+	}
+	return events
+}
+
+// LoadHistory collects events from every backend, qualifying IDs the same
+// way LoadBackends does when several backends are merged.
+func LoadHistory(backends []Backend) ([]Event, error) {
+	labels := backendLabels(backends)
+	var all []Event
+	for i, b := range backends {
+		hb, ok := b.(HistoryBackend)
+		if !ok {
+			return nil, fmt.Errorf("ticket source %s has no history", b.Name())
+		}
+		events, err := hb.History()
+		if err != nil {
+			return nil, fmt.Errorf("reading history for %s: %w", b.Name(), err)
+		}
+		if len(backends) > 1 {
+			for j := range events {
+				events[j].ID = labels[i] + ":" + events[j].ID
+			}
+		}
+		all = append(all, events...)
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Time.Before(all[j].Time) })
+	return all, nil
+}
+
+// TicketHistory summarizes the events of one ticket.
+type TicketHistory struct {
+	ID      string
+	Title   string
+	Created time.Time
+	// Closed is the time of the last transition to closed, zero if the
+	// ticket is not currently closed.
+	Closed time.Time
+	Events []Event
+}
+
+// BuildHistories groups events per ticket, ordered by creation time. Titles
+// come from items when present, falling back to the title at creation.
+func BuildHistories(events []Event, items []Ticket) []TicketHistory {
+	titles := make(map[string]string)
+	for _, it := range items {
+		titles[it.ID] = it.Title
+	}
+
+	byID := make(map[string]*TicketHistory)
+	var order []string
+	for _, ev := range events {
+		h := byID[ev.ID]
+		if h == nil {
+			h = &TicketHistory{ID: ev.ID, Title: titles[ev.ID]}
+			byID[ev.ID] = h
+			order = append(order, ev.ID)
+		}
+		h.Events = append(h.Events, ev)
+		switch ev.Kind {
+		case EventCreated:
+			if h.Created.IsZero() {
+				h.Created = ev.Time
+			}
+			if h.Title == "" {
+				h.Title = ev.Title
+			}
+		}
+		if ev.To == "closed" {
+			h.Closed = ev.Time
+		} else if ev.Kind != EventDeleted {
+			h.Closed = time.Time{}
+		}
+	}
+
+	histories := make([]TicketHistory, 0, len(order))
+	for _, id := range order {
+		histories = append(histories, *byID[id])
+	}
+	sort.SliceStable(histories, func(i, j int) bool { return histories[i].Created.Before(histories[j].Created) })
+	return histories
+}
+
+func fmtDate(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format("2006-01-02")
+}
+
+// RenderHistory prints one block per ticket: a summary line with created and
+// closed dates, then each event.
+func RenderHistory(histories []TicketHistory) string {
+	if len(histories) == 0 {
+		return "(no ticket history)\n"
+	}
+	var sb strings.Builder
+	for _, h := range histories {
+		fmt.Fprintf(&sb, "%-12s created %s  closed %s  %s\n", h.ID, fmtDate(h.Created), fmtDate(h.Closed), h.Title)
+		for _, ev := range h.Events {
+			var what string
+			switch ev.Kind {
+			case EventCreated:
+				what = fmt.Sprintf("created (%s)", ev.To)
+			case EventDeleted:
+				what = "deleted"
+			default:
+				what = fmt.Sprintf("%s -> %s", ev.From, ev.To)
+			}
+			fmt.Fprintf(&sb, "  %s %s  %s\n", ev.Time.Format("2006-01-02 15:04"), ev.Commit, what)
+		}
+	}
+	return sb.String()
+}
+
+// RunTicketHistory prints the history of every ticket, or only of ids.
+func RunTicketHistory(w io.Writer, paths []string, kind string, ids []string) error {
+	backends, err := FindBackends(paths, kind)
+	if err != nil {
+		return err
+	}
+	events, err := LoadHistory(backends)
+	if err != nil {
+		return err
+	}
+	items, err := LoadBackends(backends)
+	if err != nil {
+		return err
+	}
+
+	histories := BuildHistories(events, items)
+	if len(ids) > 0 {
+		want := make(map[string]bool)
+		for _, id := range ids {
+			want[id] = true
+		}
+		var filtered []TicketHistory
+		for _, h := range histories {
+			if want[h.ID] {
+				filtered = append(filtered, h)
+			}
+		}
+		histories = filtered
+	}
+	fmt.Fprint(w, RenderHistory(histories))
+	return nil
+}
@@ -0,0 +1,218 @@
+package tickets
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// commitAt commits all changes in dir with a fixed committer date.
+func commitAt(t *testing.T, dir, date, msg string) {
+	t.Helper()
+	for _, args := range [][]string{
+		{"git", "add", "-A"},
+		{"git", "commit", "-q", "-m", msg},
+	} {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date,
+			"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@test.com",
+			"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@test.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("%v: %s: %v", args, out, err)
+		}
+	}
+}
+
+func writeTicket(t *testing.T, dir, id, typ, status, parent, title string) {
+	t.Helper()
+	body := "---\nid: " + id + "\nstatus: " + status + "\ntype: " + typ + "\npriority: 2\n"
+	if parent != "" {
+		body += "parent: " + parent + "\n"
+	}
+	body += "---\n# " + title + "\n"
+	if err := os.WriteFile(filepath.Join(dir, ".tickets", id+".md"), []byte(body), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func setupHistoryRepo(t *testing.T) string {
+	t.Helper()
+	dir := setupTicketRepo(t)
+	writeTicket(t, dir, "E1", "epic", "open", "", "Epic")
+	writeTicket(t, dir, "A1", "task", "open", "E1", "Task A")
+	commitAt(t, dir, "2026-03-02T10:00:00Z", "create")
+	writeTicket(t, dir, "B1", "task", "open", "E1", "Task B")
+	writeTicket(t, dir, "A1", "task", "in_progress", "E1", "Task A")
+	commitAt(t, dir, "2026-03-03T10:00:00Z", "start A")
+	writeTicket(t, dir, "A1", "task", "closed", "E1", "Task A")
+	writeTicket(t, dir, "X1", "task", "open", "", "Stray")
+	commitAt(t, dir, "2026-03-05T10:00:00Z", "close A")
+	os.Remove(filepath.Join(dir, ".tickets", "X1.md"))
+	commitAt(t, dir, "2026-03-10T10:00:00Z", "drop stray")
+	return dir
+}
+
+func TestMarkdownHistory(t *testing.T) {
+	dir := setupHistoryRepo(t)
+	chdir(t, dir)
+
+	events, err := LoadHistory([]Backend{MarkdownBackend{Dir: ".tickets"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, ev := range events {
+		got = append(got, ev.ID+" "+ev.Kind+" "+ev.From+">"+ev.To)
+	}
+	want := []string{
+		"A1 created >open",
+		"E1 created >open",
+		"A1 status open>in_progress",
+		"B1 created >open",
+		"A1 status in_progress>closed",
+		"X1 created >open",
+		"X1 deleted open>",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("events:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestMarkdownHistory_IDFromFrontmatter(t *testing.T) {
+	dir := setupTicketRepo(t)
+	chdir(t, dir)
+	path := filepath.Join(dir, ".tickets", "fix-login.md")
+	os.WriteFile(path, []byte("---\nid: T7\nstatus: open\ntype: task\n---\n# Fix login\n"), 0644)
+	commitAt(t, dir, "2026-03-02T10:00:00Z", "create")
+	os.WriteFile(path, []byte("---\nid: T7\nstatus: closed\ntype: task\n---\n# Fix login\n"), 0644)
+	commitAt(t, dir, "2026-03-03T10:00:00Z", "close")
+
+	events, err := LoadHistory([]Backend{MarkdownBackend{Dir: ".tickets"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].ID != "T7" || events[1].ID != "T7" || events[1].Kind != EventStatus {
+		t.Errorf("events = %+v, want both keyed T7", events)
+	}
+}
+
+func TestBuildHistories(t *testing.T) {
+	dir := setupHistoryRepo(t)
+	chdir(t, dir)
+
+	var buf strings.Builder
+	if err := RunTicketHistory(&buf, nil, "", []string{"A1"}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "A1           created 2026-03-02  closed 2026-03-05  Task A") {
+		t.Errorf("missing summary line in:\n%s", out)
+	}
+	if !strings.Contains(out, "in_progress -> closed") {
+		t.Errorf("missing transition in:\n%s", out)
+	}
+	if strings.Contains(out, "B1") {
+		t.Errorf("history should be filtered to A1:\n%s", out)
+	}
+}
+
+func TestParseBeadsLog(t *testing.T) {
+	out := "\x00aaaaaaa1 2026-03-02T10:00:00Z\n\n" +
+		"diff --git a/.beads/issues.jsonl b/.beads/issues.jsonl\n" +
+		"--- /dev/null\n+++ b/.beads/issues.jsonl\n@@ -0,0 +1,2 @@\n" +
+		`+{"id":"B1","title":"One","issue_type":"task","status":"open"}` + "\n" +
+		`+{"id":"B2","title":"Two","issue_type":"task","status":"open"}` + "\n" +
+		"\x00bbbbbbb2 2026-03-04T10:00:00Z\n\n" +
+		"diff --git a/.beads/issues.jsonl b/.beads/issues.jsonl\n" +
+		"--- a/.beads/issues.jsonl\n+++ b/.beads/issues.jsonl\n@@ -1,2 +1 @@\n" +
+		`-{"id":"B1","title":"One","issue_type":"task","status":"open"}` + "\n" +
+		`-{"id":"B2","title":"Two","issue_type":"task","status":"open"}` + "\n" +
+		`+{"id":"B1","title":"One","issue_type":"task","status":"closed"}` + "\n"
+
+	events := parseBeadsLog(out)
+	if len(events) != 4 {
+		t.Fatalf("got %d events, want 4: %+v", len(events), events)
+	}
+	if ev := events[2]; ev.ID != "B1" || ev.Kind != EventStatus || ev.To != "closed" {
+		t.Errorf("event 2 = %+v, want B1 closed", ev)
+	}
+	if ev := events[3]; ev.ID != "B2" || ev.Kind != EventDeleted {
+		t.Errorf("event 3 = %+v, want B2 deleted", ev)
+	}
+}
+
+func TestBurndown_Daily(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 12, 0, 0, 0, time.UTC) }
+	events := []Event{
+		{Time: day(2), ID: "E1", Kind: EventCreated, To: "open", Type: "epic"},
+		{Time: day(2), ID: "A1", Kind: EventCreated, To: "open", Type: "task"},
+		{Time: day(3), ID: "B1", Kind: EventCreated, To: "open", Type: "task"},
+		{Time: day(4), ID: "A1", Kind: EventStatus, From: "open", To: "closed"},
+	}
+	points := Burndown(events, time.Time{}, day(5), false, nil)
+	if len(points) != 4 {
+		t.Fatalf("got %d points, want 4", len(points))
+	}
+	want := [][2]int{{1, 0}, {2, 0}, {1, 1}, {1, 1}}
+	for i, w := range want {
+		if points[i].Open != w[0] || points[i].Closed != w[1] {
+			t.Errorf("day %d: open %d closed %d, want %v", i, points[i].Open, points[i].Closed, w)
+		}
+	}
+}
+
+func TestBurndown_WeeklyBuckets(t *testing.T) {
+	events := []Event{
+		{Time: time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC), ID: "A", Kind: EventCreated, To: "open"},
+	}
+	points := Burndown(events, time.Time{}, time.Date(2026, 3, 17, 0, 0, 0, 0, time.UTC), true, nil)
+	if len(points) != 3 {
+		t.Fatalf("got %d points, want 3", len(points))
+	}
+	if points[0].Date.Weekday() != time.Monday || points[0].Date.Day() != 2 {
+		t.Errorf("first bucket = %s, want Monday 2026-03-02", points[0].Date)
+	}
+}
+
+func TestRunTicketBurndown_Epic(t *testing.T) {
+	dir := setupHistoryRepo(t)
+	chdir(t, dir)
+
+	var buf strings.Builder
+	if err := RunTicketBurndown(&buf, nil, "", "2026-03-02", "E1", false); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "Burndown — E1 Epic") {
+		t.Errorf("missing title in:\n%s", out)
+	}
+	if !strings.Contains(out, "2026-03-05  open    1  closed    1  #=") {
+		t.Errorf("missing 03-05 row in:\n%s", out)
+	}
+	if err := RunTicketBurndown(&buf, nil, "", "", "NOPE", false); err == nil {
+		t.Error("expected error for unknown epic")
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)
+	cases := map[string]time.Time{
+		"2026-03-01": time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		"5d":         time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC),
+		"2w":         time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC),
+	}
+	for in, want := range cases {
+		got, err := ParseSince(in, now)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("ParseSince(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseSince("yesterday", now); err == nil {
+		t.Error("expected error for invalid --since")
+	}
+}
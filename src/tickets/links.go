@@ -0,0 +1,178 @@
+package tickets
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"repotools/src/git"
+	"repotools/src/github"
+)
+
+// mentionRe matches id as a whole token, case-insensitively, so "T1" does not
+// match inside "T12" or "ST1".
+func mentionRe(id string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)(?:^|[^A-Za-z0-9])` + regexp.QuoteMeta(id) + `(?:$|[^A-Za-z0-9])`)
+}
+
+// MentionsID reports whether any of texts mentions ticket id.
+func MentionsID(id string, texts ...string) bool {
+	re := mentionRe(id)
+	for _, t := range texts {
+		if re.MatchString(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// rawID strips the "label:" prefix LoadBackends adds when merging sources,
+// since branches and commits name tickets by their unqualified ID.
+func rawID(tk Ticket) string {
+	if tk.Source != "" {
+		return strings.TrimPrefix(tk.ID, tk.Source+":")
+	}
+	return tk.ID
+}
+
+// Mentioned returns the tickets whose IDs appear in any of texts, sorted by ID.
+func Mentioned(items []Ticket, texts ...string) []Ticket {
+	var found []Ticket
+	for _, it := range items {
+		if MentionsID(rawID(it), texts...) {
+			found = append(found, it)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].ID < found[j].ID })
+	return found
+}
+
+// Links collects everything in the repo that refers to one ticket.
+type Links struct {
+	ID       string
+	Ticket   *Ticket
+	Commits  []git.Commit
+	Branches []string
+	PRs      []github.PRSummary
+	// PRError is set when PRs could not be searched (e.g. gh unavailable).
+	PRError string
+}
+
+// FindLinks searches all refs' commit messages, branch names and GitHub PRs
+// for mentions of id.
+func FindLinks(id string) (Links, error) {
+	links := Links{ID: id}
+
+	grep := `(^|[^[:alnum:]])` + regexp.QuoteMeta(id) + `([^[:alnum:]]|$)`
+	commits, err := git.Log("--all", "-i", "-E", "--grep="+grep)
+	if err != nil {
+		return links, err
+	}
+	links.Commits = commits
+
+	branches, err := git.Branches(true)
+	if err != nil {
+		return links, err
+	}
+	for _, b := range branches {
+		if MentionsID(id, b) {
+			links.Branches = append(links.Branches, b)
+		}
+	}
+
+	prs, err := github.ListPRs("--search", id)
+	if err != nil {
+		links.PRError = err.Error()
+	}
+	for _, pr := range prs {
+		if MentionsID(id, pr.Title, pr.Body, pr.HeadRefName) {
+			links.PRs = append(links.PRs, pr)
+		}
+	}
+	return links, nil
+}
+
+// RenderLinks prints the ticket followed by its commits, branches and PRs.
+func RenderLinks(l Links) string {
+	var sb strings.Builder
+	if l.Ticket != nil {
+		fmt.Fprintf(&sb, "%s [%s] %s\n", l.Ticket.ID, l.Ticket.Status, l.Ticket.Title)
+	} else {
+		fmt.Fprintf(&sb, "%s (ticket not found in sources)\n", l.ID)
+	}
+
+	fmt.Fprintf(&sb, "\nCommits (%d):\n", len(l.Commits))
+	for _, c := range l.Commits {
+		fmt.Fprintf(&sb, "  %s %s %s\n", c.Hash, c.Date, c.Subject)
+	}
+
+	fmt.Fprintf(&sb, "\nBranches (%d):\n", len(l.Branches))
+	for _, b := range l.Branches {
+		fmt.Fprintf(&sb, "  %s\n", b)
+	}
+
+	fmt.Fprintf(&sb, "\nPRs (%d):\n", len(l.PRs))
+	if l.PRError != "" {
+		fmt.Fprintf(&sb, "  (unavailable: %s)\n", l.PRError)
+	}
+	for _, pr := range l.PRs {
+		fmt.Fprintf(&sb, "  #%-5d %-7s %s  [%s]\n", pr.Number, pr.State, pr.Title, pr.HeadRefName)
+	}
+	return sb.String()
+}
+
+// RenderMentions lists tickets as "ID  status  title" rows.
+func RenderMentions(tks []Ticket) string {
+	if len(tks) == 0 {
+		return "(no tickets referenced)"
+	}
+	lines := make([]string, len(tks))
+	for i, tk := range tks {
+		lines[i] = fmt.Sprintf("  %-12s %-11s %s", tk.ID, tk.Status, tk.Title)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// BranchTexts returns the current branch name and the subjects of its
+// commits since base, the places ticket IDs are conventionally written.
+func BranchTexts(base string) []string {
+	var texts []string
+	if branch, err := git.CurrentBranch(); err == nil && branch != "" {
+		texts = append(texts, branch)
+	}
+	if mb, err := git.MergeBase(base); err == nil {
+		commits, _ := git.Log(mb + "..HEAD")
+		for _, c := range commits {
+			texts = append(texts, c.Subject)
+		}
+	}
+	return texts
+}
+
+// RunTicketLinks prints the commits, branches and PRs that mention id.
+func RunTicketLinks(w io.Writer, paths []string, kind, id string) error {
+	var ticket *Ticket
+	if items, err := LoadItems(paths, kind); err == nil {
+		for _, it := range items {
+			if it.ID == id || rawID(it) == id {
+				tk := it
+				ticket = &tk
+				break
+			}
+		}
+	}
+
+	search := id
+	if ticket != nil {
+		search = rawID(*ticket)
+	}
+	links, err := FindLinks(search)
+	if err != nil {
+		return err
+	}
+	links.Ticket = ticket
+	fmt.Fprint(w, RenderLinks(links))
+	return nil
+}
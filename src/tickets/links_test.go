@@ -0,0 +1,86 @@
+package tickets
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMentionsID(t *testing.T) {
+	cases := []struct {
+		text string
+		want bool
+	}{
+		{"feature/T1-login", true},
+		{"Fix crash (t1)", true},
+		{"T1", true},
+		{"bump T12", false},
+		{"ST1 cleanup", false},
+	}
+	for _, c := range cases {
+		if got := MentionsID("T1", c.text); got != c.want {
+			t.Errorf("MentionsID(T1, %q) = %v, want %v", c.text, got, c.want)
+		}
+	}
+}
+
+func TestMentioned_PrefixedIDs(t *testing.T) {
+	items := []Ticket{
+		{ID: "api:T1", Source: "api", Title: "Api task"},
+		{ID: "web:T2", Source: "web", Title: "Web task"},
+		{ID: "T3", Title: "Plain"},
+	}
+	got := Mentioned(items, "fix/T1-and-T3", "unrelated")
+	if len(got) != 2 || got[0].ID != "T3" || got[1].ID != "api:T1" {
+		t.Errorf("Mentioned = %v, want [T3 api:T1]", got)
+	}
+}
+
+func TestFindLinks_CommitsAndBranches(t *testing.T) {
+	dir := setupTicketRepo(t)
+	chdir(t, dir)
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a\n"), 0644)
+	commitAt(t, dir, "2026-03-02T10:00:00Z", "T1: first part")
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("b\n"), 0644)
+	commitAt(t, dir, "2026-03-03T10:00:00Z", "T12: unrelated")
+	exec.Command("git", "branch", "feature/t1-login").Run()
+
+	links, err := FindLinks("T1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(links.Commits) != 1 || links.Commits[0].Subject != "T1: first part" {
+		t.Errorf("commits = %v, want only the T1 commit", links.Commits)
+	}
+	if len(links.Branches) != 1 || links.Branches[0] != "feature/t1-login" {
+		t.Errorf("branches = %v", links.Branches)
+	}
+
+	out := RenderLinks(links)
+	if !strings.Contains(out, "Commits (1):") || !strings.Contains(out, "Branches (1):") {
+		t.Errorf("unexpected render:\n%s", out)
+	}
+}
+
+func TestBranchTexts(t *testing.T) {
+	items := loadTestItems(t)
+	dir := setupTicketRepo(t)
+	chdir(t, dir)
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a\n"), 0644)
+	commitAt(t, dir, "2026-03-02T10:00:00Z", "base")
+	exec.Command("git", "branch", "-M", "main").Run()
+	exec.Command("git", "checkout", "-q", "-b", "work/E1").Run()
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("b\n"), 0644)
+	commitAt(t, dir, "2026-03-03T10:00:00Z", "Implement T1")
+
+	texts := BranchTexts("main")
+	if strings.Join(texts, "|") != "work/E1|Implement T1" {
+		t.Errorf("BranchTexts = %q", texts)
+	}
+	got := Mentioned(items, texts...)
+	if len(got) != 2 || got[0].ID != "E1" || got[1].ID != "T1" {
+		t.Errorf("Mentioned = %v, want [E1 T1]", got)
+	}
+}
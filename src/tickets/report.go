@@ -0,0 +1,333 @@
+package tickets
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Status report output formats.
+const (
+	FormatText     = "text"
+	FormatMarkdown = "markdown"
+	FormatJSON     = "json"
+)
+
+// StatusOptions narrows and shapes a status report. The zero value shows no
+// sub-epics; DefaultStatusOptions matches the classic report.
+type StatusOptions struct {
+	// Priorities limits top-level epics and orphans to these priorities.
+	Priorities []int
+	// Epic limits the report to one epic's subtree.
+	Epic string
+	// HideOrphans drops the [Orphaned] section.
+	HideOrphans bool
+	// Depth is how many levels of sub-epics to show; negative is unlimited.
+	Depth int
+	// Percent adds a percentage-complete column.
+	Percent bool
+}
+
+func DefaultStatusOptions() StatusOptions {
+	return StatusOptions{Depth: 1}
+}
+
+// EpicStatus is one epic row with counts of its non-epic descendants.
+type EpicStatus struct {
+	ID       string       `json:"id"`
+	Title    string       `json:"title"`
+	Priority int          `json:"priority"`
+	Open     int          `json:"open"`
+	Closed   int          `json:"closed"`
+	Percent  int          `json:"percent"`
+	SubEpics []EpicStatus `json:"sub_epics,omitempty"`
+}
+
+type PriorityGroup struct {
+	Priority int          `json:"priority"`
+	Epics    []EpicStatus `json:"epics"`
+}
+
+type OrphanStatus struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Status   string `json:"status"`
+	Priority int    `json:"priority"`
+}
+
+// StatusReport is the structured form of the project status report.
+type StatusReport struct {
+	Date     string          `json:"date"`
+	Open     int             `json:"open"`
+	Closed   int             `json:"closed"`
+	Groups   []PriorityGroup `json:"groups"`
+	Orphans  []OrphanStatus  `json:"orphans,omitempty"`
+	Percents bool            `json:"-"`
+}
+
+func percent(open, closed int) int {
+	if open+closed == 0 {
+		return 0
+	}
+	return closed * 100 / (open + closed)
+}
+
+// BuildStatus groups open top-level epics by priority with their sub-epics,
+// and collects open tickets that have no open epic parent.
+func BuildStatus(items []Ticket, today string, opts StatusOptions) (StatusReport, error) {
+	children := make(map[string][]Ticket)
+	for _, it := range items {
+		if it.Parent != "" {
+			children[it.Parent] = append(children[it.Parent], it)
+		}
+	}
+
+	epics := make(map[string]Ticket)
+	for _, it := range items {
+		if it.IsEpic() && it.Status != "closed" {
+			epics[it.ID] = it
+		}
+	}
+
+	var topEpics []Ticket
+	if opts.Epic != "" {
+		var found bool
+		for _, it := range items {
+			if it.ID == opts.Epic && it.IsEpic() {
+				topEpics = append(topEpics, it)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return StatusReport{}, fmt.Errorf("epic %s not found", opts.Epic)
+		}
+	} else {
+		childEpicIDs := make(map[string]bool)
+		for pid := range epics {
+			for _, child := range children[pid] {
+				if child.IsEpic() {
+					childEpicIDs[child.ID] = true
+				}
+			}
+		}
+		for id, ep := range epics {
+			if !childEpicIDs[id] {
+				topEpics = append(topEpics, ep)
+			}
+		}
+	}
+
+	wantPri := func(p int) bool {
+		if len(opts.Priorities) == 0 {
+			return true
+		}
+		for _, w := range opts.Priorities {
+			if w == p {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Count open/closed non-epic descendants
+	descendantCounts := func(eid string) (open, closed int) {
+		stack := []string{eid}
+		for len(stack) > 0 {
+			id := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, child := range children[id] {
+				if child.IsEpic() {
+					stack = append(stack, child.ID)
+				} else if child.Status == "closed" {
+					closed++
+				} else {
+					open++
+				}
+			}
+		}
+		return open, closed
+	}
+
+	var epicStatus func(ep Ticket, depth int) EpicStatus
+	epicStatus = func(ep Ticket, depth int) EpicStatus {
+		es := EpicStatus{ID: ep.ID, Title: ep.Title, Priority: ep.Priority}
+		es.Open, es.Closed = descendantCounts(ep.ID)
+		es.Percent = percent(es.Open, es.Closed)
+		if opts.Depth >= 0 && depth >= opts.Depth {
+			return es
+		}
+		var subs []Ticket
+		for _, child := range children[ep.ID] {
+			if child.IsEpic() && child.Status != "closed" {
+				subs = append(subs, child)
+			}
+		}
+		sort.Slice(subs, func(i, j int) bool { return subs[i].Title < subs[j].Title })
+		for _, sub := range subs {
+			es.SubEpics = append(es.SubEpics, epicStatus(sub, depth+1))
+		}
+		return es
+	}
+
+	report := StatusReport{Date: today, Percents: opts.Percent}
+
+	// Group top-level epics by priority
+	epicsByPri := make(map[int][]Ticket)
+	for _, e := range topEpics {
+		if opts.Epic != "" || wantPri(e.Priority) {
+			epicsByPri[e.Priority] = append(epicsByPri[e.Priority], e)
+		}
+	}
+	var pris []int
+	for p := range epicsByPri {
+		pris = append(pris, p)
+	}
+	sort.Ints(pris)
+
+	for _, p := range pris {
+		pEpics := epicsByPri[p]
+		sort.Slice(pEpics, func(i, j int) bool { return pEpics[i].Title < pEpics[j].Title })
+		group := PriorityGroup{Priority: p}
+		for _, ep := range pEpics {
+			group.Epics = append(group.Epics, epicStatus(ep, 0))
+		}
+		report.Groups = append(report.Groups, group)
+	}
+
+	// Orphaned tickets: non-epic, non-closed, with no parent or parent not a known epic
+	if !opts.HideOrphans && opts.Epic == "" {
+		for _, it := range items {
+			if it.IsEpic() || it.Status == "closed" || !wantPri(it.Priority) {
+				continue
+			}
+			if _, ok := epics[it.Parent]; it.Parent == "" || !ok {
+				report.Orphans = append(report.Orphans, OrphanStatus{ID: it.ID, Title: it.Title, Status: it.Status, Priority: it.Priority})
+			}
+		}
+		sort.Slice(report.Orphans, func(i, j int) bool { return report.Orphans[i].Title < report.Orphans[j].Title })
+	}
+
+	// Totals cover the whole project, or under --epic and --priority only
+	// the tickets the report shows.
+	if opts.Epic == "" && len(opts.Priorities) == 0 {
+		for _, it := range items {
+			if it.Status == "closed" {
+				report.Closed++
+			} else {
+				report.Open++
+			}
+		}
+		return report, nil
+	}
+	for _, p := range pris {
+		for _, ep := range epicsByPri[p] {
+			open, closed := descendantCounts(ep.ID)
+			report.Open += open
+			report.Closed += closed
+		}
+	}
+	report.Open += len(report.Orphans)
+	return report, nil
+}
+
+// RenderStatus renders a report as text, markdown or json.
+func RenderStatus(r StatusReport, format string) (string, error) {
+	switch format {
+	case "", FormatText:
+		return renderStatusText(r), nil
+	case FormatMarkdown, "md":
+		return renderStatusMarkdown(r), nil
+	case FormatJSON:
+		data, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(data) + "\n", nil
+	}
+	return "", fmt.Errorf("unknown format %q (want %s, %s or %s)", format, FormatText, FormatMarkdown, FormatJSON)
+}
+
+func renderStatusText(r StatusReport) string {
+	var out []string
+	out = append(out, fmt.Sprintf("Project Status — %s  (%d open, %d closed)", r.Date, r.Open, r.Closed), "")
+
+	var addEpic func(es EpicStatus, depth int)
+	addEpic = func(es EpicStatus, depth int) {
+		indent := strings.Repeat("  ", depth+1)
+		counts := fmt.Sprintf("%d/%d", es.Open, es.Closed)
+		if r.Percents {
+			out = append(out, fmt.Sprintf("%s%-12s %-7s %4s %s", indent, es.ID, counts, fmt.Sprintf("%d%%", es.Percent), es.Title))
+		} else {
+			out = append(out, fmt.Sprintf("%s%-12s %-7s %s", indent, es.ID, counts, es.Title))
+		}
+		for _, sub := range es.SubEpics {
+			addEpic(sub, depth+1)
+		}
+	}
+
+	for _, g := range r.Groups {
+		out = append(out, fmt.Sprintf("[P%d]", g.Priority))
+		for _, es := range g.Epics {
+			addEpic(es, 0)
+		}
+		out = append(out, "")
+	}
+
+	if len(r.Orphans) > 0 {
+		out = append(out, "[Orphaned]")
+		for _, o := range r.Orphans {
+			out = append(out, fmt.Sprintf("  %-12s %-7s %s", o.ID, o.Status, o.Title))
+		}
+		out = append(out, "")
+	}
+
+	return strings.Join(out, "\n")
+}
+
+func renderStatusMarkdown(r StatusReport) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## Project Status — %s (%d open, %d closed)\n", r.Date, r.Open, r.Closed)
+
+	if len(r.Groups) > 0 {
+		sb.WriteString("\n| Pri | Epic | Open | Closed |")
+		if r.Percents {
+			sb.WriteString(" Done |")
+		}
+		sb.WriteString(" Title |\n|-----|------|------|--------|")
+		if r.Percents {
+			sb.WriteString("------|")
+		}
+		sb.WriteString("-------|\n")
+
+		var addEpic func(pri int, es EpicStatus, depth int)
+		addEpic = func(pri int, es EpicStatus, depth int) {
+			fmt.Fprintf(&sb, "| P%d | %s%s | %d | %d |", pri, strings.Repeat("↳ ", depth), es.ID, es.Open, es.Closed)
+			if r.Percents {
+				fmt.Fprintf(&sb, " %d%% |", es.Percent)
+			}
+			fmt.Fprintf(&sb, " %s |\n", mdEscape(es.Title))
+			for _, sub := range es.SubEpics {
+				addEpic(pri, sub, depth+1)
+			}
+		}
+		for _, g := range r.Groups {
+			for _, es := range g.Epics {
+				addEpic(g.Priority, es, 0)
+			}
+		}
+	}
+
+	if len(r.Orphans) > 0 {
+		sb.WriteString("\n### Orphaned\n\n| ID | Status | Title |\n|----|--------|-------|\n")
+		for _, o := range r.Orphans {
+			fmt.Fprintf(&sb, "| %s | %s | %s |\n", o.ID, o.Status, mdEscape(o.Title))
+		}
+	}
+	return sb.String()
+}
+
+func mdEscape(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
@@ -0,0 +1,129 @@
+package tickets
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func deepItems() []Ticket {
+	return []Ticket{
+		{ID: "A", Title: "Alpha", Type: "epic", Status: "open", Priority: 1},
+		{ID: "B", Title: "Beta", Type: "epic", Status: "open", Priority: 1, Parent: "A"},
+		{ID: "C", Title: "Gamma", Type: "epic", Status: "open", Priority: 1, Parent: "B"},
+		{ID: "t1", Title: "Deep task", Type: "task", Status: "closed", Priority: 2, Parent: "C"},
+		{ID: "t2", Title: "Deep task 2", Type: "task", Status: "open", Priority: 2, Parent: "C"},
+		{ID: "Z", Title: "Zeta", Type: "epic", Status: "open", Priority: 3},
+		{ID: "o1", Title: "Loose", Type: "task", Status: "open", Priority: 3},
+	}
+}
+
+func TestBuildStatus_Depth(t *testing.T) {
+	opts := DefaultStatusOptions()
+	r, err := BuildStatus(deepItems(), "2026-02-28", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out := renderStatusText(r); strings.Contains(out, "Gamma") {
+		t.Errorf("default depth should stop at one sub-epic level:\n%s", out)
+	}
+
+	opts.Depth = -1
+	r, _ = BuildStatus(deepItems(), "2026-02-28", opts)
+	out := renderStatusText(r)
+	if !strings.Contains(out, "      C            1/1     Gamma") {
+		t.Errorf("full depth should show nested Gamma:\n%s", out)
+	}
+}
+
+func TestBuildStatus_PriorityFilter(t *testing.T) {
+	opts := DefaultStatusOptions()
+	opts.Priorities = []int{3}
+	r, _ := BuildStatus(deepItems(), "2026-02-28", opts)
+	out := renderStatusText(r)
+	if strings.Contains(out, "Alpha") || !strings.Contains(out, "Zeta") || !strings.Contains(out, "Loose") {
+		t.Errorf("priority filter wrong:\n%s", out)
+	}
+	if r.Open != 1 || r.Closed != 0 {
+		t.Errorf("totals = %d open, %d closed; want only the shown orphan", r.Open, r.Closed)
+	}
+}
+
+func TestBuildStatus_EpicSubtree(t *testing.T) {
+	opts := DefaultStatusOptions()
+	opts.Epic = "B"
+	r, err := BuildStatus(deepItems(), "2026-02-28", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := renderStatusText(r)
+	if strings.Contains(out, "Alpha") || strings.Contains(out, "Zeta") || strings.Contains(out, "[Orphaned]") {
+		t.Errorf("epic filter should only show B's subtree:\n%s", out)
+	}
+	if !strings.Contains(out, "Beta") || !strings.Contains(out, "Gamma") {
+		t.Errorf("missing subtree rows:\n%s", out)
+	}
+	if !strings.Contains(out, "(1 open, 1 closed)") {
+		t.Errorf("header should count B's subtree only:\n%s", out)
+	}
+
+	opts.Epic = "nope"
+	if _, err := BuildStatus(deepItems(), "2026-02-28", opts); err == nil {
+		t.Error("expected error for unknown epic")
+	}
+}
+
+func TestBuildStatus_HideOrphans(t *testing.T) {
+	opts := DefaultStatusOptions()
+	opts.HideOrphans = true
+	r, _ := BuildStatus(deepItems(), "2026-02-28", opts)
+	if len(r.Orphans) != 0 {
+		t.Errorf("orphans = %v, want none", r.Orphans)
+	}
+}
+
+func TestRenderStatus_PercentText(t *testing.T) {
+	opts := DefaultStatusOptions()
+	opts.Percent = true
+	r, _ := BuildStatus(deepItems(), "2026-02-28", opts)
+	out, err := RenderStatus(r, FormatText)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "  A            1/1      50% Alpha") {
+		t.Errorf("missing percent column:\n%s", out)
+	}
+}
+
+func TestRenderStatus_Markdown(t *testing.T) {
+	opts := DefaultStatusOptions()
+	opts.Depth = -1
+	r, _ := BuildStatus(deepItems(), "2026-02-28", opts)
+	out, err := RenderStatus(r, FormatMarkdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"| Pri | Epic | Open | Closed | Title |", "| P1 | ↳ ↳ C | 1 | 1 | Gamma |", "### Orphaned", "| o1 | open | Loose |"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderStatus_JSON(t *testing.T) {
+	r, _ := BuildStatus(deepItems(), "2026-02-28", DefaultStatusOptions())
+	out, err := RenderStatus(r, FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded StatusReport
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if decoded.Open != 6 || len(decoded.Groups) != 2 || decoded.Groups[0].Epics[0].Percent != 50 {
+		t.Errorf("decoded = %+v", decoded)
+	}
+	if _, err := RenderStatus(r, "xml"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"io"
 	"os"
 	"path/filepath"
-	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -20,6 +19,7 @@ type Ticket struct {
 	Priority int
 	Parent   string
 	Tags     []string
+	Source   string
 }
 
 func (t Ticket) IsEpic() bool {
@@ -132,156 +132,38 @@ func LoadTickets(dir string) ([]Ticket, error) {
 	return tickets, nil
 }
 
+// BuildStatusReport renders the classic text report: open top-level epics by
+// priority with one level of sub-epics, then orphaned tickets.
 func BuildStatusReport(items []Ticket, today string) string {
-	children := make(map[string][]Ticket)
-	for _, it := range items {
-		if it.Parent != "" {
-			children[it.Parent] = append(children[it.Parent], it)
-		}
-	}
-
-	epics := make(map[string]Ticket)
-	for _, it := range items {
-		if it.IsEpic() && it.Status != "closed" {
-			epics[it.ID] = it
-		}
-	}
-
-	childEpicIDs := make(map[string]bool)
-	for pid := range epics {
-		for _, child := range children[pid] {
-			if child.IsEpic() {
-				childEpicIDs[child.ID] = true
-			}
-		}
-	}
-	var topEpics []Ticket
-	for id, ep := range epics {
-		if !childEpicIDs[id] {
-			topEpics = append(topEpics, ep)
-		}
-	}
-
-	// Collect direct sub-epics for each epic
-	subEpicsOf := func(eid string) []Ticket {
-		var subs []Ticket
-		for _, child := range children[eid] {
-			if child.IsEpic() && child.Status != "closed" {
-				subs = append(subs, child)
-			}
-		}
-		sort.Slice(subs, func(i, j int) bool { return subs[i].Title < subs[j].Title })
-		return subs
-	}
-
-	// Count open/closed non-epic descendants
-	type counts struct{ open, closed int }
-	descendantCounts := func(eid string) counts {
-		var c counts
-		stack := []string{eid}
-		for len(stack) > 0 {
-			id := stack[len(stack)-1]
-			stack = stack[:len(stack)-1]
-			for _, child := range children[id] {
-				if child.IsEpic() {
-					stack = append(stack, child.ID)
-				} else if child.Status == "closed" {
-					c.closed++
-				} else {
-					c.open++
-				}
-			}
-		}
-		return c
-	}
-
-	var totalOpen, totalClosed int
-	for _, it := range items {
-		if it.Status == "closed" {
-			totalClosed++
-		} else {
-			totalOpen++
-		}
-	}
-
-	var out []string
-	out = append(out, fmt.Sprintf("Project Status — %s  (%d open, %d closed)", today, totalOpen, totalClosed), "")
-
-	// Group top-level epics by priority
-	epicsByPri := make(map[int][]Ticket)
-	for _, e := range topEpics {
-		epicsByPri[e.Priority] = append(epicsByPri[e.Priority], e)
-	}
-	var pris []int
-	for p := range epicsByPri {
-		pris = append(pris, p)
-	}
-	sort.Ints(pris)
-
-	for _, p := range pris {
-		pEpics := epicsByPri[p]
-		sort.Slice(pEpics, func(i, j int) bool { return pEpics[i].Title < pEpics[j].Title })
-
-		out = append(out, fmt.Sprintf("[P%d]", p))
-
-		for _, ep := range pEpics {
-			c := descendantCounts(ep.ID)
-			out = append(out, fmt.Sprintf("  %-12s %-7s %s", ep.ID, fmt.Sprintf("%d/%d", c.open, c.closed), ep.Title))
-
-			for _, sub := range subEpicsOf(ep.ID) {
-				sc := descendantCounts(sub.ID)
-				out = append(out, fmt.Sprintf("    %-12s %-7s %s", sub.ID, fmt.Sprintf("%d/%d", sc.open, sc.closed), sub.Title))
-			}
-		}
-		out = append(out, "")
-	}
+	r, _ := BuildStatus(items, today, DefaultStatusOptions())
+	return renderStatusText(r)
+}
 
-	// Orphaned tickets: non-epic, non-closed, with no parent or parent not a known epic
-	var orphans []Ticket
-	for _, it := range items {
-		if it.IsEpic() || it.Status == "closed" {
-			continue
-		}
-		if it.Parent == "" {
-			orphans = append(orphans, it)
-		} else if _, ok := epics[it.Parent]; !ok {
-			// Parent exists but isn't a known (non-closed) epic — also orphaned
-			orphans = append(orphans, it)
-		}
-	}
-	if len(orphans) > 0 {
-		sort.Slice(orphans, func(i, j int) bool { return orphans[i].Title < orphans[j].Title })
-		out = append(out, "[Orphaned]")
-		for _, o := range orphans {
-			out = append(out, fmt.Sprintf("  %-12s %-7s %s", o.ID, o.Status, o.Title))
-		}
-		out = append(out, "")
+// LoadItems resolves the ticket backends for paths and kind (see
+// FindBackends) and loads their merged tickets.
+func LoadItems(paths []string, kind string) ([]Ticket, error) {
+	backends, err := FindBackends(paths, kind)
+	if err != nil {
+		return nil, err
 	}
-
-	return strings.Join(out, "\n")
+	return LoadBackends(backends)
 }
 
-func findTicketsDir() (string, error) {
-	dir := ".tickets"
-	info, err := os.Stat(dir)
-	if err != nil || !info.IsDir() {
-		return "", fmt.Errorf("no .tickets/ directory found")
+func RunTicketStatus(w io.Writer, paths []string, kind string, opts StatusOptions, format string) error {
+	items, err := LoadItems(paths, kind)
+	if err != nil {
+		return fmt.Errorf("loading tickets: %w", err)
 	}
-	return dir, nil
-}
 
-func RunTicketStatus(w io.Writer) error {
-	dir, err := findTicketsDir()
+	today := time.Now().Format("2006-01-02")
+	report, err := BuildStatus(items, today, opts)
 	if err != nil {
 		return err
 	}
-
-	items, err := LoadTickets(dir)
+	out, err := RenderStatus(report, format)
 	if err != nil {
-		return fmt.Errorf("loading tickets: %w", err)
+		return err
 	}
-
-	today := time.Now().Format("2006-01-02")
-	fmt.Fprint(w, BuildStatusReport(items, today))
+	fmt.Fprint(w, out)
 	return nil
 }